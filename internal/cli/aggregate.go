@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/parser"
+)
+
+const (
+	defaultAggWorkers   = 1
+	defaultAggBatchSize = 1
+)
+
+func HandlerAggregate(s *State, cmd Command) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 3 {
+		return fmt.Errorf("agg expects 1 to 3 arguments: the interval at which to aggregate, and optionally the number of concurrent fetches and the number of feeds to fetch per interval")
+	}
+
+	fmt.Println("Fetching every ", cmd.Args[0])
+	interval, err := time.ParseDuration(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	workers := defaultAggWorkers
+	if len(cmd.Args) > 1 {
+		workers, err = strconv.Atoi(cmd.Args[1])
+		if err != nil {
+			return err
+		}
+		if workers < 1 {
+			return fmt.Errorf("number of concurrent fetches must be at least 1")
+		}
+	}
+
+	// by default every worker gets one feed per tick
+	batchSize := max(workers, defaultAggBatchSize)
+	if len(cmd.Args) > 2 {
+		batchSize, err = strconv.Atoi(cmd.Args[2])
+		if err != nil {
+			return err
+		}
+		if batchSize < 1 {
+			return fmt.Errorf("batch size must be at least 1")
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	for ; ; <-ticker.C {
+		// a broken feed shouldn't stop the others, errors are recorded per feed
+		err = scrapeFeeds(s, workers, batchSize)
+		if err != nil {
+			fmt.Printf("error fetching feeds: %v\n", err)
+		}
+	}
+}
+
+// scrapeFeeds claims up to batchSize feeds that are due and fetches them
+// with at most workers running at once. Claiming uses SKIP LOCKED, so several
+// agg processes can share one database without fetching the same feed.
+func scrapeFeeds(s *State, workers, batchSize int) error {
+	now := time.Now()
+	feeds, err := s.Db.ClaimFeedsToFetch(context.Background(),
+		database.ClaimFeedsToFetchParams{
+			LeaseUntil: sql.NullTime{
+				Time:  now.Add(fetchLease),
+				Valid: true,
+			},
+			Now: sql.NullTime{
+				Time:  now,
+				Valid: true,
+			},
+			BatchSize: int32(batchSize),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// jobs carries indexes into feeds so each worker writes its own slot of errs
+	jobs := make(chan int)
+	errs := make([]error, len(feeds))
+	var wg sync.WaitGroup
+	for range min(workers, len(feeds)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := scrapeFeed(s, feeds[i])
+				if err != nil {
+					errs[i] = fmt.Errorf("%s: %w", feeds[i].Url, err)
+				}
+			}
+		}()
+	}
+	for i := range feeds {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func scrapeFeed(s *State, nextFeed database.Feed) error {
+	feedURL := nextFeed.Url
+	cache := parser.CacheHeaders{
+		ETag:         nextFeed.Etag,
+		LastModified: nextFeed.LastModified,
+	}
+
+	fetchedFeed, err := parser.FetchFeed(context.Background(), feedURL, cache)
+	if errors.Is(err, parser.ErrNotModified) {
+		// nothing new, so judge the posting rate by what we already have
+		postDates, err := s.Db.GetPostDatesForFeed(context.Background(),
+			database.GetPostDatesForFeedParams{
+				FeedID: nextFeed.ID,
+				Limit:  postDateSamples,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		hint := time.Duration(nextFeed.UpdateHintSeconds) * time.Second
+		return scheduleNextFetch(s, nextFeed, cache, hint, postDates)
+	}
+	if err != nil {
+		return errors.Join(err, recordFeedError(s, nextFeed, err))
+	}
+
+	// the cache headers are only saved once every item is stored, otherwise the
+	// next fetch would be told nothing changed and skip the items still missing
+	if fetchedFeed.Link != "" && fetchedFeed.Link != nextFeed.SiteUrl {
+		err = s.Db.SetFeedSiteURL(context.Background(),
+			database.SetFeedSiteURLParams{
+				ID:      nextFeed.ID,
+				SiteUrl: fetchedFeed.Link,
+			},
+		)
+		if err != nil {
+			return errors.Join(err, recordFeedError(s, nextFeed, err))
+		}
+	}
+
+	var created, updated, unchanged int
+	for _, post := range fetchedFeed.Items {
+		if postGUID(post) == "" {
+			continue
+		}
+		inserted, err := storePost(s, nextFeed, post)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			unchanged++
+		case err != nil:
+			return errors.Join(err, recordFeedError(s, nextFeed, err))
+		case inserted:
+			created++
+		default:
+			updated++
+		}
+	}
+
+	var postDates []time.Time
+	for _, post := range fetchedFeed.Items {
+		if !post.DateEstimated {
+			postDates = append(postDates, post.Published)
+		}
+	}
+	err = scheduleNextFetch(s, nextFeed, fetchedFeed.Cache, fetchedFeed.UpdateHint, postDates)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d new, %d updated, %d unchanged\n", nextFeed.Name, created, updated, unchanged)
+	return nil
+}
+
+// storePost inserts post into feed, or updates the stored post with its guid.
+// Like UpsertPost it returns sql.ErrNoRows when the post is stored unchanged.
+func storePost(s *State, feed database.Feed, post parser.Item) (bool, error) {
+	guid := sql.NullString{String: postGUID(post), Valid: true}
+	if post.Link != "" {
+		err := s.Db.AdoptPostGUID(context.Background(),
+			database.AdoptPostGUIDParams{
+				FeedID: feed.ID,
+				Url:    post.Link,
+				Guid:   guid,
+			},
+		)
+		if err != nil {
+			return false, err
+		}
+	}
+	return s.Db.UpsertPost(context.Background(),
+		database.UpsertPostParams{
+			ID:                   uuid.New(),
+			CreatedAt:            time.Now(),
+			UpdatedAt:            time.Now(),
+			Title:                post.Title,
+			Url:                  post.Link,
+			Description:          post.Description,
+			PublishedAt:          post.Published,
+			FeedID:               feed.ID,
+			PublishedAtEstimated: post.DateEstimated,
+			Guid:                 guid,
+		},
+	)
+}
+
+// postGUID identifies a post within its feed. Not every feed gives its items
+// an id, so fall back to the link, and then the title.
+func postGUID(post parser.Item) string {
+	for _, guid := range []string{post.ID, post.Link, post.Title} {
+		guid = strings.TrimSpace(guid)
+		if guid != "" {
+			return guid
+		}
+	}
+	return ""
+}
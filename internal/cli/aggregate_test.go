@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/dbtest"
+	"github.com/quanchobi/gator/internal/parser"
+)
+
+// A post stored before posts had guids is adopted by the item at its url, even
+// when the item's id isn't its link, instead of being stored a second time.
+func TestStorePostAdoptsPostWithoutGUID(t *testing.T) {
+	db := dbtest.Open(t)
+	dbtest.Migrate(t, db, 0, 9)
+	now := time.Now()
+	userID, feedID := uuid.New(), uuid.New()
+	dbtest.Exec(t, db, "INSERT INTO users (id, created_at, updated_at, name) VALUES ($1, $2, $2, 'kahya')", userID, now)
+	dbtest.Exec(t, db, "INSERT INTO feeds (id, created_at, updated_at, name, url, user_id) VALUES ($1, $2, $2, 'blog', 'https://example.com/feed', $3)", feedID, now, userID)
+	dbtest.Exec(t, db, "INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id) VALUES ($1, $2, $2, 'A', 'https://example.com/a', 'old', $2, $3)", uuid.New(), now, feedID)
+	dbtest.MigrateAll(t, db)
+
+	s := &State{Db: database.New(db)}
+	feed, err := s.Db.GetFeedByURL(context.Background(), "https://example.com/feed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := parser.Item{
+		ID:          "tag:example.com,2024:a",
+		Title:       "A",
+		Link:        "https://example.com/a",
+		Description: "new",
+		Published:   now,
+	}
+
+	inserted, err := storePost(s, feed, item)
+	if err != nil || inserted {
+		t.Fatalf("storePost() = %v, %v, want the stored post updated", inserted, err)
+	}
+	_, err = storePost(s, feed, item)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("storing the item again: %v, want it unchanged", err)
+	}
+
+	var count int
+	var guid string
+	err = db.QueryRow("SELECT COUNT(*), MAX(guid) FROM posts WHERE feed_id = $1", feedID).Scan(&count, &guid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || guid != item.ID {
+		t.Errorf("%d posts with guid %q, want 1 with %q", count, guid, item.ID)
+	}
+}
@@ -1,21 +1,31 @@
 package cli
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/quanchobi/gator/internal/config"
+	"github.com/quanchobi/gator/internal/cursor"
 	"github.com/quanchobi/gator/internal/database"
-	"github.com/quanchobi/gator/internal/parser"
+	"github.com/quanchobi/gator/internal/render"
 )
 
+const defaultPostLimit = 2
+
 type State struct {
 	Cfg *config.Config
 	Db  *database.Queries
+	// Conn is the connection Db runs on, for transactions
+	Conn   *sql.DB
+	Output Output
 }
 
 type Command struct {
@@ -40,6 +50,20 @@ func GetFunctions() map[string]func(*State, Command) error {
 		"following": MiddlewareLoggedIn(HandlerFollowing),
 		"unfollow":  MiddlewareLoggedIn(HandlerUnfollow),
 		"browse":    MiddlewareLoggedIn(HandlerBrowse),
+		"interval":  MiddlewareLoggedIn(HandlerInterval),
+		"enable":    MiddlewareLoggedIn(HandlerEnable),
+		"read":      MiddlewareLoggedIn(HandlerRead),
+		"unread":    MiddlewareLoggedIn(HandlerUnread),
+		"star":      MiddlewareLoggedIn(HandlerStar),
+		"unstar":    MiddlewareLoggedIn(HandlerUnstar),
+		"starred":   MiddlewareLoggedIn(HandlerStarred),
+		"import":    MiddlewareLoggedIn(HandlerImport),
+		"export":    MiddlewareLoggedIn(HandlerExport),
+		"search":    MiddlewareLoggedIn(HandlerSearch),
+		"publish":   MiddlewareLoggedIn(HandlerPublish),
+		"tui":       MiddlewareLoggedIn(HandlerTUI),
+		"serve":     HandlerServe,
+		"token":     MiddlewareLoggedIn(HandlerToken),
 	}
 }
 
@@ -107,98 +131,70 @@ func HandlerReset(s *State, cmd Command) error {
 	return nil
 }
 
-func HandlerUsers(s *State, cmd Command) error {
-	users, err := s.Db.GetUsers(context.Background())
-	if err != nil {
-		return err
-	}
-	for _, user := range users {
-		if user.Name == s.Cfg.CurrentUserName {
-			fmt.Printf("* %v (current)\n", user.Name)
-		} else {
-			fmt.Printf("* %v\n", user.Name)
-		}
-	}
-	return nil
+type userRow struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Current   bool      `json:"current"`
 }
 
-func HandlerAggregate(s *State, cmd Command) error {
-	if len(cmd.Args) != 1 {
-		return fmt.Errorf("agg expects 1 argument, the interval at which to aggregate")
-	}
-
-	fmt.Println("Fetching every ", cmd.Args[0])
-	interval, err := time.ParseDuration(cmd.Args[0])
+func HandlerUsers(s *State, cmd Command) error {
+	users, err := s.Db.GetUsers(context.Background())
 	if err != nil {
 		return err
 	}
 
-	ticker := time.NewTicker(interval)
-	for ; ; <-ticker.C {
-		err = scrapeFeeds(s)
-		if err != nil {
-			return err
+	rows := make([]userRow, 0, len(users))
+	for _, user := range users {
+		rows = append(rows, userRow{
+			Name:      user.Name,
+			CreatedAt: user.CreatedAt,
+			Current:   user.Name == s.Cfg.CurrentUserName,
+		})
+	}
+
+	return printRows(s, rows, func() error {
+		for _, user := range rows {
+			if user.Current {
+				fmt.Printf("* %v (current)\n", user.Name)
+			} else {
+				fmt.Printf("* %v\n", user.Name)
+			}
 		}
-	}
+		return nil
+	})
 }
 
-func scrapeFeeds(s *State) error {
-	nextFeed, err := s.Db.GetNextFeedToFetch(context.Background())
-	if err != nil {
-		return err
+func HandlerAddFeed(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("addfeed takes two arguments: name of the feed and url")
 	}
-	feedURL := nextFeed.Url
+	feedName := cmd.Args[0]
+	url := resolveFeedURL(cmd.Args[1])
 
-	fetchedFeed, err := parser.FetchFeed(context.Background(), feedURL)
+	feed, feedFollow, err := addFeed(s, user, feedName, url, "", "")
 	if err != nil {
 		return err
 	}
 
-	s.Db.MarkFeedFetched(context.Background(),
-		database.MarkFeedFetchedParams{
-			ID: nextFeed.ID,
-			LastFetchedAt: sql.NullTime{
-				Time:  time.Now(),
-				Valid: true,
-			},
-			UpdatedAt: time.Now(),
-		},
-	)
-
-	for _, post := range fetchedFeed.Channel.Item {
-		postTime, err := time.Parse(time.RFC1123Z, post.PubDate)
-		if err != nil {
-			fmt.Println(post.PubDate)
-		}
-
-		_, err = s.Db.CreatePost(context.Background(),
-			database.CreatePostParams{
-				ID:          uuid.New(),
-				CreatedAt:   time.Now(),
-				UpdatedAt:   time.Now(),
-				Title:       post.Title,
-				Url:         post.Link,
-				Description: post.Description,
-				PublishedAt: postTime,
-				FeedID:      nextFeed.ID,
-			},
-		)
-		if err != nil {
-			return err
-		}
-	}
+	fmt.Printf("Created feed record: %v, at: %v, %v (%v), for user %v\n", feed.ID, feed.CreatedAt, feed.Name, feed.Url, feed.UserID)
+	fmt.Printf("Created following record: %v, for user %v and feed %v\n", feedFollow.ID, feedFollow.UserID, feedFollow.FeedID)
 
 	return nil
 }
 
-func HandlerAddFeed(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) != 2 {
-		return fmt.Errorf("addfeed takes two arguments: name of the feed and url")
+// addFeed creates a feed and follows it for the user who added it, filed
+// under folder.
+// addFeed creates a feed and follows it for user, in one transaction so a
+// failure can't leave a feed nobody follows. siteURL is optional.
+func addFeed(s *State, user database.User, feedName, url, folder, siteURL string) (database.Feed, database.CreateFeedFollowRow, error) {
+	tx, err := s.Conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return database.Feed{}, database.CreateFeedFollowRow{}, err
 	}
-	feedName := cmd.Args[0]
-	url := cmd.Args[1]
+	defer tx.Rollback()
+	q := s.Db.WithTx(tx)
 
-	feed, err := s.Db.CreateFeed(context.Background(),
+	feed, err := q.CreateFeed(context.Background(),
 		database.CreateFeedParams{
 			ID:        uuid.New(),
 			CreatedAt: time.Now(),
@@ -213,26 +209,49 @@ func HandlerAddFeed(s *State, cmd Command, user database.User) error {
 		},
 	)
 	if err != nil {
-		fmt.Printf("Error in creating feed %v \n", err)
-		return err
+		return database.Feed{}, database.CreateFeedFollowRow{}, fmt.Errorf("error in creating feed: %w", err)
 	}
 
-	feedFollow, err := s.Db.CreateFeedFollow(context.Background(),
+	if siteURL != "" {
+		err = q.SetFeedSiteURL(context.Background(),
+			database.SetFeedSiteURLParams{
+				ID:      feed.ID,
+				SiteUrl: siteURL,
+			},
+		)
+		if err != nil {
+			return database.Feed{}, database.CreateFeedFollowRow{}, err
+		}
+		feed.SiteUrl = siteURL
+	}
+
+	feedFollow, err := q.CreateFeedFollow(context.Background(),
 		database.CreateFeedFollowParams{
 			ID:     uuid.New(),
 			UserID: user.ID,
 			FeedID: feed.ID,
+			Folder: folder,
 		},
 	)
 	if err != nil {
-		fmt.Println("Error in creating feed follows")
-		return err
+		return database.Feed{}, database.CreateFeedFollowRow{}, fmt.Errorf("error in creating feed follows: %w", err)
 	}
 
-	fmt.Printf("Created feed record: %v, at: %v, %v (%v), for user %v\n", feed.ID, feed.CreatedAt, feed.Name, feed.Url, feed.UserID)
-	fmt.Printf("Created following record: %v, for user %v and feed %v\n", feedFollow.ID, feedFollow.UserID, feedFollow.FeedID)
+	err = tx.Commit()
+	if err != nil {
+		return database.Feed{}, database.CreateFeedFollowRow{}, err
+	}
+	return feed, feedFollow, nil
+}
 
-	return nil
+type feedRow struct {
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	AddedBy       string     `json:"added_by"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	FailureCount  int32      `json:"failure_count"`
+	LastError     string     `json:"last_error" table:"-"`
+	Disabled      bool       `json:"disabled"`
 }
 
 func HandlerPrintFeeds(s *State, cmd Command) error {
@@ -243,10 +262,34 @@ func HandlerPrintFeeds(s *State, cmd Command) error {
 	if err != nil {
 		return err
 	}
+
+	rows := make([]feedRow, 0, len(feeds))
 	for _, feed := range feeds {
-		fmt.Printf("%v, %v: %v\n", feed.Username, feed.Name, feed.Url)
+		row := feedRow{
+			Name:         feed.Name,
+			URL:          feed.Url,
+			AddedBy:      feed.Username,
+			FailureCount: feed.FailureCount,
+			LastError:    feed.LastError,
+			Disabled:     feed.Disabled,
+		}
+		if feed.LastFetchedAt.Valid {
+			row.LastFetchedAt = &feed.LastFetchedAt.Time
+		}
+		rows = append(rows, row)
 	}
-	return nil
+
+	return printRows(s, rows, func() error {
+		for _, feed := range rows {
+			fmt.Printf("%v, %v: %v\n", feed.AddedBy, feed.Name, feed.URL)
+			if feed.Disabled {
+				fmt.Printf("    disabled after %d failed fetches: %s\n", feed.FailureCount, feed.LastError)
+			} else if feed.FailureCount > 0 {
+				fmt.Printf("    %d failed fetches: %s\n", feed.FailureCount, feed.LastError)
+			}
+		}
+		return nil
+	})
 }
 
 func HandlerFollow(s *State, cmd Command, user database.User) error {
@@ -254,7 +297,7 @@ func HandlerFollow(s *State, cmd Command, user database.User) error {
 		return fmt.Errorf("follow takes one argument: the URL")
 	}
 
-	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	feed, err := findFeed(s, cmd.Args[0])
 	if err != nil {
 		return err
 	}
@@ -274,17 +317,34 @@ func HandlerFollow(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
+type followRow struct {
+	Feed   string `json:"feed"`
+	URL    string `json:"url"`
+	Folder string `json:"folder"`
+}
+
 func HandlerFollowing(s *State, cmd Command, user database.User) error {
 	follows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("%s is following:\n", user.Name)
+	rows := make([]followRow, 0, len(follows))
 	for _, follow := range follows {
-		fmt.Printf("%s (%s)\n", follow.Feedname, follow.Url)
+		rows = append(rows, followRow{
+			Feed:   follow.Feedname,
+			URL:    follow.Url,
+			Folder: follow.Folder,
+		})
 	}
-	return nil
+
+	return printRows(s, rows, func() error {
+		fmt.Printf("%s is following:\n", user.Name)
+		for _, follow := range rows {
+			fmt.Printf("%s (%s)\n", follow.Feed, follow.URL)
+		}
+		return nil
+	})
 }
 
 func HandlerUnfollow(s *State, cmd Command, user database.User) error {
@@ -306,37 +366,241 @@ func HandlerUnfollow(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
+type postRow struct {
+	ID            uuid.UUID `json:"id"`
+	Title         string    `json:"title"`
+	URL           string    `json:"url"`
+	Feed          string    `json:"feed"`
+	FeedURL       string    `json:"feed_url" table:"-"`
+	PublishedAt   time.Time `json:"published_at"`
+	DateEstimated bool      `json:"date_estimated" table:"-"`
+	Read          bool      `json:"read"`
+	Starred       bool      `json:"starred"`
+	Description   string    `json:"description" table:"-"`
+	// Cursor is what --after takes to continue after this post
+	Cursor string `json:"cursor" table:"-"`
+}
+
 func HandlerBrowse(s *State, cmd Command, user database.User) error {
-	var limit int
-	var err error
-	if len(cmd.Args) > 1 {
-		return fmt.Errorf("browse takes 0 or 1 arguments, if you pass 1 in, it is the number of posts showed. If not, it defaults to 2")
-	}
-	if len(cmd.Args) < 1 {
-		limit = 2
-	} else {
-		limit, err = strconv.Atoi(cmd.Args[0])
+	fs := flag.NewFlagSet("browse", flag.ContinueOnError)
+	all := fs.Bool("all", false, "include posts that have already been read")
+	unread := fs.Bool("unread", false, "only show posts that haven't been read, the default")
+	markRead := fs.Bool("mark-read", false, "mark the shown posts as read")
+	feedArg := fs.String("feed", "", "only show posts from the followed feed with this url or name")
+	since := fs.String("since", "", "only show posts published since this date or age, e.g. 48h")
+	until := fs.String("until", "", "only show posts published before this date or age")
+	match := fs.String("match", "", "only show posts with this text in their title or description")
+	after := fs.String("after", "", "start after the post with this cursor, as printed at the end of the previous page")
+	page := fs.Bool("page", false, "keep showing pages of posts, waiting for enter in between")
+	args, err := parseArgs(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if *all && *unread {
+		return fmt.Errorf("browse takes either --all or --unread, not both")
+	}
+	// every page is printed on its own, which would make several json arrays
+	// or csv headers
+	if *page && (s.Output.Format == "json" || s.Output.Format == "csv") {
+		return fmt.Errorf("--page can't be used with %s output, use jsonl instead", s.Output.Format)
+	}
+
+	limit, err := parsePostLimit(cmd.Name, args)
+	if err != nil {
+		return err
+	}
+	if limit < 1 {
+		return fmt.Errorf("the number of posts to show must be at least 1")
+	}
+	params := database.GetPostsForUserParams{
+		UserID:      user.ID,
+		IncludeRead: *all,
+		Match:       *match,
+		Limit:       int32(limit), // if you overflow here I salute you. I will not be putting in overflow guards. Normal use case would be to not display 2^32 or more posts at a time.
+	}
+	if *feedArg != "" {
+		follow, err := findFollowedFeed(s, user, *feedArg)
 		if err != nil {
 			return err
 		}
+		params.FeedID = uuid.NullUUID{UUID: follow.FeedID, Valid: true}
 	}
-	posts, err := s.Db.GetPostsForUser(context.Background(),
-		database.GetPostsForUserParams{
-			UserID: user.ID,
-			Limit:  int32(limit), // if you overflow here I salute you. I will not be putting in overflow guards. Normal use case would be to not display 2^32 or more posts at a time.
-		},
-	)
+	if *since != "" {
+		t, err := parseTimeArg(*since)
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+	if *until != "" {
+		t, err := parseTimeArg(*until)
+		if err != nil {
+			return err
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+
+	if *after != "" {
+		publishedAt, id, err := cursor.Decode(*after)
+		if err != nil {
+			return err
+		}
+		params.AfterPublishedAt = sql.NullTime{Time: publishedAt, Valid: true}
+		params.AfterID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	input := bufio.NewScanner(os.Stdin)
+	for {
+		next, err := browsePage(s, user, params, *markRead)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+
+		if !*page {
+			// outside of text output the hint would break the format
+			hint := os.Stdout
+			if s.Output.Format != "text" {
+				hint = os.Stderr
+			}
+			// the same filters, with ages turned into the times they stood for
+			values := map[string]string{"after": next}
+			if params.Since.Valid {
+				values["since"] = params.Since.Time.Format(time.RFC3339)
+			}
+			if params.Until.Valid {
+				values["until"] = params.Until.Time.Format(time.RFC3339)
+			}
+			fmt.Fprintf(hint, "more posts: %s\n", commandLine(fs, values, strconv.Itoa(limit)))
+			return nil
+		}
+		fmt.Fprint(os.Stderr, "-- more, enter to continue or q to quit --")
+		if !input.Scan() || strings.TrimSpace(input.Text()) == "q" {
+			return nil
+		}
+
+		publishedAt, id, err := cursor.Decode(next)
+		if err != nil {
+			return err
+		}
+		params.AfterPublishedAt = sql.NullTime{Time: publishedAt, Valid: true}
+		params.AfterID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+}
+
+// browsePage prints one page of posts and returns the cursor of the next
+// page, empty if this was the last one.
+func browsePage(s *State, user database.User, params database.GetPostsForUserParams, markRead bool) (string, error) {
+	// fetch one extra post to know if there is a next page
+	limit := int(params.Limit)
+	params.Limit++
+	posts, err := s.Db.GetPostsForUser(context.Background(), params)
 	if err != nil {
-		return err
+		return "", err
+	}
+	next := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1]
+		next = cursor.Encode(last.PublishedAt, last.ID)
 	}
 
+	rows := make([]postRow, 0, len(posts))
 	for _, post := range posts {
-		fmt.Println(post.Title)
-		fmt.Println(post.PublishedAt)
-		fmt.Println(post.Description)
+		rows = append(rows, postRow{
+			ID:            post.ID,
+			Title:         post.Title,
+			URL:           post.Url,
+			Feed:          post.FeedName,
+			FeedURL:       post.FeedUrl,
+			PublishedAt:   post.PublishedAt,
+			DateEstimated: post.PublishedAtEstimated,
+			Read:          post.Read,
+			Starred:       post.Starred,
+			Description:   post.Description,
+			Cursor:        cursor.Encode(post.PublishedAt, post.ID),
+		})
+	}
+
+	err = printRows(s, rows, func() error {
+		opts := render.OptionsFor(os.Stdout)
+		for _, post := range rows {
+			switch {
+			case post.Starred:
+				fmt.Println("*", post.Title)
+			case post.Read:
+				fmt.Println(post.Title, "(read)")
+			default:
+				fmt.Println(post.Title)
+			}
+			fmt.Printf("%s (%s)\n", post.Feed, post.FeedURL)
+			fmt.Println(post.ID)
+			if post.DateEstimated {
+				fmt.Println(post.PublishedAt, "(date estimated)")
+			} else {
+				fmt.Println(post.PublishedAt)
+			}
+			opts.BaseURL = post.URL
+			fmt.Println(render.HTML(post.Description, opts))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	return nil
+	if !markRead {
+		return next, nil
+	}
+	for _, post := range posts {
+		if post.Read {
+			continue
+		}
+		err = s.Db.SetPostRead(context.Background(),
+			database.SetPostReadParams{
+				UserID: user.ID,
+				PostID: post.ID,
+				Read:   true,
+				ReadAt: sql.NullTime{
+					Time:  time.Now(),
+					Valid: true,
+				},
+			},
+		)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return next, nil
+}
+
+// findFollowedFeed finds one of the feeds the user follows by its url or,
+// ignoring case, its name.
+func findFollowedFeed(s *State, user database.User, feed string) (database.GetFeedFollowsForUserRow, error) {
+	follows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return database.GetFeedFollowsForUserRow{}, err
+	}
+	for _, follow := range follows {
+		if follow.Url == feed || strings.EqualFold(follow.Feedname, feed) {
+			return follow, nil
+		}
+	}
+	return database.GetFeedFollowsForUserRow{}, fmt.Errorf("you don't follow a feed with url or name %s", feed)
+}
+
+// parsePostLimit reads the optional number of posts listing commands show.
+func parsePostLimit(name string, args []string) (int, error) {
+	if len(args) > 1 {
+		return 0, fmt.Errorf("%s takes 0 or 1 arguments, if you pass 1 in, it is the number of posts showed. If not, it defaults to %d", name, defaultPostLimit)
+	}
+	if len(args) < 1 {
+		return defaultPostLimit, nil
+	}
+	return strconv.Atoi(args[0])
 }
 
 func MiddlewareLoggedIn(handler func(s *State, cmd Command, user database.User) error) func(*State, Command) error {
@@ -346,8 +610,7 @@ func MiddlewareLoggedIn(handler func(s *State, cmd Command, user database.User)
 			return err
 		}
 
-		handler(s, cmd, user)
-		return nil
+		return handler(s, cmd, user)
 	}
 }
 
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/parser"
+)
+
+// resolveFeedURL turns a url that might be a web page into the url of a feed.
+// When the page advertises several feeds the first one is picked and the
+// rest are listed. If nothing can be discovered the url is used as given.
+func resolveFeedURL(pageURL string) string {
+	found, err := parser.DiscoverFeeds(context.Background(), pageURL)
+	if err != nil {
+		fmt.Printf("could not discover a feed at %s, using it as is: %v\n", pageURL, err)
+		return pageURL
+	}
+
+	if found[0].URL != pageURL {
+		fmt.Printf("found feed %s at %s\n", found[0].URL, pageURL)
+	}
+	if len(found) > 1 {
+		fmt.Println("other feeds on that page:")
+		for _, feed := range found[1:] {
+			if feed.Title != "" {
+				fmt.Printf("  %s (%s)\n", feed.URL, feed.Title)
+			} else {
+				fmt.Printf("  %s\n", feed.URL)
+			}
+		}
+	}
+	return found[0].URL
+}
+
+// findFeed looks up a feed by url, and if there is none, by the urls of the
+// feeds discovered at that url.
+func findFeed(s *State, feedURL string) (database.Feed, error) {
+	feed, err := s.Db.GetFeedByURL(context.Background(), feedURL)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return feed, err
+	}
+
+	found, discoverErr := parser.DiscoverFeeds(context.Background(), feedURL)
+	if discoverErr != nil {
+		return database.Feed{}, fmt.Errorf("no feed with url %s, add it with addfeed", feedURL)
+	}
+	for _, candidate := range found {
+		feed, err := s.Db.GetFeedByURL(context.Background(), candidate.URL)
+		if err == nil {
+			fmt.Printf("found feed %s at %s\n", feed.Url, feedURL)
+			return feed, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return database.Feed{}, err
+		}
+	}
+	return database.Feed{}, fmt.Errorf("no feed with url %s, add %s with addfeed", feedURL, found[0].URL)
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quanchobi/gator/internal/parser"
+)
+
+// parseArgs parses args with fs and returns the positional arguments. Unlike
+// fs.Parse, flags may come after positional arguments, e.g. "browse 10 --all".
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// commandLine rebuilds a gator command from the flags set in fs and args, to
+// suggest running it again. Flags in values are given those values instead,
+// and added if they weren't set, unless empty.
+func commandLine(fs *flag.FlagSet, values map[string]string, args ...string) string {
+	words := []string{"gator", fs.Name()}
+	add := func(name, value string) {
+		if value != "" {
+			words = append(words, "--"+name, shellQuote(value))
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if value, ok := values[f.Name]; ok {
+			add(f.Name, value)
+			delete(values, f.Name)
+			return
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			if f.Value.String() == "true" {
+				words = append(words, "--"+f.Name)
+			} else {
+				words = append(words, "--"+f.Name+"="+f.Value.String())
+			}
+			return
+		}
+		add(f.Name, f.Value.String())
+	})
+	fs.VisitAll(func(f *flag.Flag) {
+		if value, ok := values[f.Name]; ok {
+			add(f.Name, value)
+		}
+	})
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes s for a POSIX shell, if it needs it.
+func shellQuote(s string) string {
+	safe := s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@%+=,", r)
+	}) < 0
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// parseTimeArg reads a point in time given either as a date or as an age
+// relative to now, such as 48h or 7d.
+func parseTimeArg(value string) (time.Time, error) {
+	age, err := parseAge(value)
+	if err == nil {
+		return time.Now().UTC().Add(-age), nil
+	}
+	t, err := parser.ParseDate(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date nor a duration", value)
+	}
+	return t, nil
+}
+
+// parseAge is time.ParseDuration with support for whole days, e.g. 7d.
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct {
+		args   []string
+		values map[string]string
+		want   string
+	}{
+		{nil, map[string]string{"after": "abc"}, "gator browse --after abc 10"},
+		{
+			[]string{"--feed", "Hacker News", "--all", "--match", "it's"},
+			map[string]string{"after": "abc"},
+			`gator browse --all --feed 'Hacker News' --match 'it'\''s' --after abc 10`,
+		},
+		// the cursor of the next page replaces the one given
+		{[]string{"--after", "old", "--since", "48h"}, map[string]string{"after": "new", "since": "2024-05-01T00:00:00Z"},
+			"gator browse --after new --since 2024-05-01T00:00:00Z 10"},
+		{[]string{"--unread=false"}, map[string]string{"after": ""}, "gator browse --unread=false 10"},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("browse", flag.ContinueOnError)
+		fs.Bool("all", false, "")
+		fs.Bool("unread", false, "")
+		fs.String("feed", "", "")
+		fs.String("since", "", "")
+		fs.String("match", "", "")
+		fs.String("after", "", "")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if got := commandLine(fs, tt.values, "10"); got != tt.want {
+			t.Errorf("commandLine(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
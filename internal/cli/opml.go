@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/opml"
+)
+
+func HandlerImport(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("import takes one argument: the path of an OPML file")
+	}
+
+	file, err := os.Open(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	doc, err := opml.Parse(file)
+	if err != nil {
+		return fmt.Errorf("could not parse %s: %w", cmd.Args[0], err)
+	}
+
+	follows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	following := make(map[uuid.UUID]bool, len(follows))
+	for _, follow := range follows {
+		following[follow.FeedID] = true
+	}
+
+	var created, existing, failed int
+	for _, sub := range doc.Subscriptions() {
+		name := sub.Name
+		if name == "" {
+			name = sub.URL
+		}
+
+		feed, err := s.Db.GetFeedByURL(context.Background(), sub.URL)
+		if errors.Is(err, sql.ErrNoRows) {
+			// the site is kept until agg fetches the feed and finds its own link
+			_, _, err = addFeed(s, user, name, sub.URL, sub.Folder, sub.SiteURL)
+			if err != nil {
+				fmt.Printf("failed: %s (%s): %v\n", name, sub.URL, err)
+				failed++
+				continue
+			}
+			fmt.Printf("created: %s (%s)\n", name, sub.URL)
+			created++
+			continue
+		}
+		if err != nil {
+			fmt.Printf("failed: %s (%s): %v\n", name, sub.URL, err)
+			failed++
+			continue
+		}
+
+		if !following[feed.ID] {
+			_, err = s.Db.CreateFeedFollow(context.Background(),
+				database.CreateFeedFollowParams{
+					ID:     uuid.New(),
+					UserID: user.ID,
+					FeedID: feed.ID,
+					Folder: sub.Folder,
+				},
+			)
+			if err != nil {
+				fmt.Printf("failed: %s (%s): %v\n", name, sub.URL, err)
+				failed++
+				continue
+			}
+			following[feed.ID] = true
+		}
+		fmt.Printf("existing: %s (%s)\n", feed.Name, feed.Url)
+		existing++
+	}
+
+	fmt.Printf("imported %s: %d created, %d existing, %d failed\n", cmd.Args[0], created, existing, failed)
+	return nil
+}
+
+func HandlerExport(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("export takes 0 or 1 arguments: the file to write to, if not given the OPML is written to stdout")
+	}
+
+	follows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	subs := make([]opml.Subscription, 0, len(follows))
+	for _, follow := range follows {
+		subs = append(subs, opml.Subscription{
+			Name:    follow.Feedname,
+			URL:     follow.Url,
+			SiteURL: follow.SiteUrl,
+			Folder:  follow.Folder,
+		})
+	}
+	doc := opml.New(fmt.Sprintf("%s's gator subscriptions", user.Name), subs)
+
+	if len(cmd.Args) == 0 {
+		return doc.Write(os.Stdout)
+	}
+
+	file, err := os.Create(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	err = doc.Write(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("exported %d feeds to %s\n", len(subs), cmd.Args[0])
+	return nil
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+// Output is how listing commands print their rows, picked with the global
+// --output option.
+type Output struct {
+	// Format is text, table, json, jsonl, csv or template
+	Format   string
+	Template *template.Template
+}
+
+// ParseOutputFlag takes --output (or -o) off the front of args, before the
+// command name. Arguments of the command are left alone, so they can be
+// anything, even -o.
+func ParseOutputFlag(args []string) (Output, []string, error) {
+	value := ""
+	for len(args) > 0 {
+		arg := args[0]
+		if v, ok := strings.CutPrefix(arg, "--output="); ok {
+			value = v
+			args = args[1:]
+			continue
+		}
+		if arg != "--output" && arg != "-o" {
+			break
+		}
+		if len(args) == 1 {
+			return Output{}, nil, fmt.Errorf("%s needs a format: text, table, json, jsonl, csv or template=<go template>", arg)
+		}
+		value = args[1]
+		args = args[2:]
+	}
+
+	output, err := parseOutput(value)
+	return output, args, err
+}
+
+func parseOutput(value string) (Output, error) {
+	switch value {
+	case "", "text":
+		return Output{Format: "text"}, nil
+	case "table", "json", "jsonl", "csv":
+		return Output{Format: value}, nil
+	}
+
+	// a bare template is fine too, as long as it is obviously one
+	text, ok := strings.CutPrefix(value, "template=")
+	if !ok {
+		if !strings.Contains(value, "{{") {
+			return Output{}, fmt.Errorf("unknown output format %s, expected text, table, json, jsonl, csv or template=<go template>", value)
+		}
+		text = value
+	}
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return Output{}, fmt.Errorf("invalid output template: %w", err)
+	}
+	return Output{Format: "template", Template: tmpl}, nil
+}
+
+// printRows writes rows, a slice of structs, in the chosen output format.
+// text prints them the way the command always has. Fields are named by their
+// json tag, and left out of tables when tagged table:"-".
+func printRows[T any](s *State, rows []T, text func() error) error {
+	if rows == nil {
+		rows = []T{}
+	}
+	w := os.Stdout
+
+	switch s.Output.Format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		for _, row := range rows {
+			err := encoder.Encode(row)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		return writeCSV(w, rows)
+	case "table":
+		return writeTable(w, rows)
+	case "template":
+		for _, row := range rows {
+			err := s.Output.Template.Execute(w, row)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	default:
+		return text()
+	}
+}
+
+type column struct {
+	name  string
+	index int
+	table bool
+}
+
+func columns(t reflect.Type) []column {
+	var cols []column
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		cols = append(cols, column{
+			name:  name,
+			index: i,
+			table: field.Tag.Get("table") != "-",
+		})
+	}
+	return cols
+}
+
+func writeCSV[T any](w io.Writer, rows []T) error {
+	cols := columns(reflect.TypeFor[T]())
+	writer := csv.NewWriter(w)
+
+	record := make([]string, len(cols))
+	for i, col := range cols {
+		record[i] = col.name
+	}
+	err := writer.Write(record)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		v := reflect.ValueOf(row)
+		for i, col := range cols {
+			record[i] = formatField(v.Field(col.index))
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeTable[T any](w io.Writer, rows []T) error {
+	var cols []column
+	for _, col := range columns(reflect.TypeFor[T]()) {
+		if col.table {
+			cols = append(cols, col)
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = strings.ToUpper(col.name)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range rows {
+		v := reflect.ValueOf(row)
+		cells := make([]string, len(cols))
+		for i, col := range cols {
+			// a tab or newline would break the table
+			cells[i] = strings.Join(strings.Fields(formatField(v.Field(col.index))), " ")
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func formatField(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v.Interface())
+}
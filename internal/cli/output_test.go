@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOutputFlag(t *testing.T) {
+	tests := []struct {
+		args   []string
+		format string
+		rest   []string
+	}{
+		{[]string{"feeds"}, "text", []string{"feeds"}},
+		{[]string{"-o", "json", "feeds"}, "json", []string{"feeds"}},
+		{[]string{"--output=csv", "browse", "20"}, "csv", []string{"browse", "20"}},
+		// arguments of the command are not options of gator
+		{[]string{"star", "-o", "note", "text"}, "text", []string{"star", "-o", "note", "text"}},
+		{[]string{"--output", "table", "browse", "--output", "x"}, "table", []string{"browse", "--output", "x"}},
+	}
+	for _, tt := range tests {
+		output, rest, err := ParseOutputFlag(tt.args)
+		if err != nil {
+			t.Errorf("ParseOutputFlag(%q): %v", tt.args, err)
+			continue
+		}
+		if output.Format != tt.format || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("ParseOutputFlag(%q) = %s %q, want %s %q", tt.args, output.Format, rest, tt.format, tt.rest)
+		}
+	}
+
+	_, _, err := ParseOutputFlag([]string{"-o"})
+	if err == nil {
+		t.Error("expected an error for -o without a format")
+	}
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/feedgen"
+)
+
+const defaultPublishLimit = 50
+
+// HandlerPublish writes every followed feed merged into one RSS or Atom feed,
+// for other tools to read.
+func HandlerPublish(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	format := fs.String("format", "rss", "feed format, rss or atom")
+	limit := fs.Int("limit", defaultPublishLimit, "number of posts in the feed")
+	link := fs.String("link", "", "url the feed will be published at")
+	args, err := parseArgs(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("publish takes 0 or 1 arguments: the file to write to, if not given the feed is written to stdout")
+	}
+
+	write := feedgen.WriteRSS
+	switch *format {
+	case "rss":
+	case "atom":
+		write = feedgen.WriteAtom
+	default:
+		return fmt.Errorf("unknown format %s, expected rss or atom", *format)
+	}
+
+	posts, err := s.Db.GetPostsForUser(context.Background(),
+		database.GetPostsForUserParams{
+			UserID:      user.ID,
+			IncludeRead: true,
+			Limit:       int32(*limit),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	feed := feedgen.UserFeed(user, posts, *link)
+
+	if len(args) == 0 {
+		return write(os.Stdout, feed)
+	}
+
+	file, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	err = write(file, feed)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("published %d posts to %s\n", len(posts), args[0])
+	return nil
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+)
+
+func HandlerRead(s *State, cmd Command, user database.User) error {
+	return setRead(s, cmd, user, true)
+}
+
+func HandlerUnread(s *State, cmd Command, user database.User) error {
+	return setRead(s, cmd, user, false)
+}
+
+// setRead marks posts read or unread for user. Posts are given by id or url,
+// or selected with --feed <url> or --older <date|age>.
+func setRead(s *State, cmd Command, user database.User, read bool) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	feedURL := fs.String("feed", "", "mark every post of the followed feed with this URL or name")
+	older := fs.String("older", "", "mark every post published before this date or age (e.g. 7d)")
+	posts, err := parseArgs(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+
+	selectors := 0
+	for _, set := range []bool{*feedURL != "", *older != "", len(posts) > 0} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		return fmt.Errorf("%s takes either post ids or urls, --feed <url|name>, or --older <date|age>", cmd.Name)
+	}
+
+	readAt := sql.NullTime{
+		Time:  time.Now(),
+		Valid: read,
+	}
+
+	var count int64
+	switch {
+	case *feedURL != "":
+		follow, err := findFollowedFeed(s, user, *feedURL)
+		if err != nil {
+			return err
+		}
+		count, err = s.Db.SetFeedPostsRead(context.Background(),
+			database.SetFeedPostsReadParams{
+				UserID: user.ID,
+				Read:   read,
+				ReadAt: readAt,
+				FeedID: follow.FeedID,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	case *older != "":
+		before, err := parseTimeArg(*older)
+		if err != nil {
+			return err
+		}
+		count, err = s.Db.SetPostsReadBefore(context.Background(),
+			database.SetPostsReadBeforeParams{
+				UserID:      user.ID,
+				Read:        read,
+				ReadAt:      readAt,
+				PublishedAt: before,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	default:
+		for _, arg := range posts {
+			post, err := lookupPost(s, user, arg)
+			if err != nil {
+				return fmt.Errorf("could not find post %s: %w", arg, err)
+			}
+			err = s.Db.SetPostRead(context.Background(),
+				database.SetPostReadParams{
+					UserID: user.ID,
+					PostID: post.ID,
+					Read:   read,
+					ReadAt: readAt,
+				},
+			)
+			if err != nil {
+				return err
+			}
+			count++
+		}
+	}
+
+	state := "read"
+	if !read {
+		state = "unread"
+	}
+	fmt.Printf("marked %d posts %s\n", count, state)
+	return nil
+}
+
+// lookupPost finds a post in the feeds user follows by its id, or failing that
+// by its url.
+func lookupPost(s *State, user database.User, idOrURL string) (database.GetPostByIDRow, error) {
+	id, err := uuid.Parse(idOrURL)
+	if err == nil {
+		return s.Db.GetPostByID(context.Background(),
+			database.GetPostByIDParams{
+				ID:     id,
+				UserID: user.ID,
+			},
+		)
+	}
+	post, err := s.Db.GetPostByURL(context.Background(),
+		database.GetPostByURLParams{
+			Url:    idOrURL,
+			UserID: user.ID,
+		},
+	)
+	return database.GetPostByIDRow(post), err
+}
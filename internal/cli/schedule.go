@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/parser"
+)
+
+const (
+	minPollInterval     = 5 * time.Minute
+	maxPollInterval     = 24 * time.Hour
+	defaultPollInterval = time.Hour
+	// fetchLease is how long a claimed feed is hidden from other agg processes
+	fetchLease = 15 * time.Minute
+	// postDateSamples is how many recent posts are used to estimate how often a feed posts
+	postDateSamples = 20
+	// failing feeds are retried after minErrorBackoff, doubling with every
+	// consecutive failure up to maxErrorBackoff
+	minErrorBackoff = 5 * time.Minute
+	maxErrorBackoff = 24 * time.Hour
+)
+
+// pollInterval decides how long to wait before fetching a feed again. An
+// interval configured on the feed always wins. Otherwise poll at twice the
+// rate the feed has been posting at, but never sooner than the publisher's
+// hint asks for, and always within [minPollInterval, maxPollInterval].
+func pollInterval(feed database.Feed, hint time.Duration, postDates []time.Time) time.Duration {
+	if feed.PollIntervalSeconds.Valid {
+		return time.Duration(feed.PollIntervalSeconds.Int32) * time.Second
+	}
+
+	interval := defaultPollInterval
+	if observed := postingInterval(postDates); observed > 0 {
+		interval = observed / 2
+	}
+	interval = max(interval, hint)
+
+	return min(max(interval, minPollInterval), maxPollInterval)
+}
+
+// errorBackoff is how long to wait before retrying a feed that has failed
+// failures times in a row.
+func errorBackoff(failures int32) time.Duration {
+	backoff := minErrorBackoff
+	for i := int32(1); i < failures && backoff < maxErrorBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxErrorBackoff)
+}
+
+// postingInterval is the average time between the given posts, or 0 if there
+// are too few distinct dates to tell.
+func postingInterval(dates []time.Time) time.Duration {
+	sorted := slices.Clone(dates)
+	slices.SortFunc(sorted, func(a, b time.Time) int { return b.Compare(a) })
+	sorted = slices.CompactFunc(sorted, func(a, b time.Time) bool { return a.Equal(b) })
+	if len(sorted) > postDateSamples {
+		sorted = sorted[:postDateSamples]
+	}
+	if len(sorted) < 2 {
+		return 0
+	}
+	span := sorted[0].Sub(sorted[len(sorted)-1])
+	return span / time.Duration(len(sorted)-1)
+}
+
+func HandlerInterval(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return fmt.Errorf("interval takes the URL of a feed, and optionally a polling interval (e.g. 30m) or \"auto\" to let gator decide")
+	}
+
+	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(cmd.Args) == 1 {
+		if feed.PollIntervalSeconds.Valid {
+			fmt.Printf("%s is polled every %v\n", feed.Name, time.Duration(feed.PollIntervalSeconds.Int32)*time.Second)
+		} else {
+			fmt.Printf("%s is polled automatically\n", feed.Name)
+		}
+		if feed.NextFetchAt.Valid {
+			fmt.Printf("next fetch at %v\n", feed.NextFetchAt.Time)
+		}
+		return nil
+	}
+
+	if feed.UserID != user.ID {
+		return fmt.Errorf("only the user who added %s can change its polling interval", feed.Name)
+	}
+
+	var seconds sql.NullInt32
+	if cmd.Args[1] != "auto" {
+		interval, err := time.ParseDuration(cmd.Args[1])
+		if err != nil {
+			return err
+		}
+		if interval < time.Minute {
+			return fmt.Errorf("polling interval must be at least 1m")
+		}
+		seconds = sql.NullInt32{
+			Int32: int32(interval / time.Second),
+			Valid: true,
+		}
+	}
+
+	err = s.Db.SetFeedPollInterval(context.Background(),
+		database.SetFeedPollIntervalParams{
+			ID:                  feed.ID,
+			PollIntervalSeconds: seconds,
+			UpdatedAt:           time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("polling interval for %s set to %s\n", feed.Name, cmd.Args[1])
+	return nil
+}
+
+// scheduleNextFetch records a completed fetch of feed and when to fetch it next.
+func scheduleNextFetch(s *State, feed database.Feed, cache parser.CacheHeaders, hint time.Duration, postDates []time.Time) error {
+	now := time.Now()
+	next := now.Add(pollInterval(feed, hint, postDates))
+	return s.Db.MarkFeedFetched(context.Background(),
+		database.MarkFeedFetchedParams{
+			ID: feed.ID,
+			LastFetchedAt: sql.NullTime{
+				Time:  now,
+				Valid: true,
+			},
+			UpdatedAt:    now,
+			Etag:         cache.ETag,
+			LastModified: cache.LastModified,
+			NextFetchAt: sql.NullTime{
+				Time:  next,
+				Valid: true,
+			},
+			UpdateHintSeconds: int32(hint / time.Second),
+		},
+	)
+}
+
+// recordFeedError stores a failed fetch of feed, backing off exponentially and
+// disabling the feed once it has failed too many times in a row.
+func recordFeedError(s *State, feed database.Feed, fetchErr error) error {
+	now := time.Now()
+	failures := feed.FailureCount + 1
+	disabled := int(failures) >= s.Cfg.FeedFailureLimit()
+	if disabled {
+		fmt.Printf("disabling %s after %d failed fetches, last error: %v\n", feed.Url, failures, fetchErr)
+	}
+	return s.Db.RecordFeedError(context.Background(),
+		database.RecordFeedErrorParams{
+			ID:           feed.ID,
+			FailureCount: failures,
+			LastError:    fetchErr.Error(),
+			LastErrorAt: sql.NullTime{
+				Time:  now,
+				Valid: true,
+			},
+			NextFetchAt: sql.NullTime{
+				Time:  now.Add(errorBackoff(failures)),
+				Valid: true,
+			},
+			Disabled:  disabled,
+			UpdatedAt: now,
+		},
+	)
+}
+
+func HandlerEnable(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("enable takes one argument: the URL of a disabled feed")
+	}
+
+	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	if feed.UserID != user.ID {
+		following, err := followsFeed(s, user, feed)
+		if err != nil {
+			return err
+		}
+		if !following {
+			return fmt.Errorf("only the user who added %s or its followers can enable it", feed.Name)
+		}
+	}
+
+	err = s.Db.EnableFeed(context.Background(),
+		database.EnableFeedParams{
+			ID:        feed.ID,
+			UpdatedAt: time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s enabled, it will be fetched on the next agg tick\n", feed.Name)
+	return nil
+}
+
+func followsFeed(s *State, user database.User, feed database.Feed) (bool, error) {
+	follows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, follow := range follows {
+		if follow.FeedID == feed.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+)
+
+const defaultSearchLimit = 10
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// matchStart and matchStop surround the matches in a search snippet. They are
+// control characters, which can't be confused with the text of a post.
+const (
+	matchStart = "\x02"
+	matchStop  = "\x03"
+)
+
+type searchRow struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Feed        string    `json:"feed"`
+	PublishedAt time.Time `json:"published_at"`
+	Rank        float32   `json:"rank"`
+	Snippet     string    `json:"snippet" table:"-"`
+}
+
+func HandlerSearch(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := fs.Int("limit", defaultSearchLimit, "maximum number of posts to show")
+	words, err := parseArgs(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("search takes a query, e.g. gator search go generics -java, \"exact phrase\" or \"a or b\"")
+	}
+
+	posts, err := s.Db.SearchPostsForUser(context.Background(),
+		database.SearchPostsForUserParams{
+			Query:  strings.Join(words, " "),
+			UserID: user.ID,
+			Limit:  int32(*limit),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// matches are highlighted in text output only
+	start, stop := "", ""
+	if s.Output.Format == "text" && isTerminal(os.Stdout) {
+		start, stop = "\033[1m", "\033[0m"
+	}
+	rows := make([]searchRow, 0, len(posts))
+	for _, post := range posts {
+		snippet := htmlTag.ReplaceAllString(post.Snippet, "")
+		snippet = strings.Join(strings.Fields(snippet), " ")
+		snippet = strings.NewReplacer(matchStart, start, matchStop, stop).Replace(snippet)
+		rows = append(rows, searchRow{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Feed:        post.FeedName,
+			PublishedAt: post.PublishedAt,
+			Rank:        post.Rank,
+			Snippet:     snippet,
+		})
+	}
+
+	return printRows(s, rows, func() error {
+		for _, post := range rows {
+			fmt.Printf("%s (%s)\n", post.Title, post.Feed)
+			fmt.Println(post.ID)
+			fmt.Println(post.PublishedAt)
+			fmt.Printf("    %s\n", post.Snippet)
+		}
+		if len(rows) == 0 {
+			fmt.Println("no posts found")
+		}
+		return nil
+	})
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/server"
+)
+
+const defaultServeAddr = ":8080"
+
+func HandlerServe(s *State, cmd Command) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("serve takes 0 or 1 arguments: the address to listen on, defaults to %s", defaultServeAddr)
+	}
+	addr := defaultServeAddr
+	if len(cmd.Args) == 1 {
+		addr = cmd.Args[0]
+	}
+
+	return server.New(s.Db).ListenAndServe(addr)
+}
+
+type tokenRow struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Scope     string    `json:"scope"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func HandlerToken(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return fmt.Errorf("token takes a subcommand: create [name], feed, fever <password>, greader <password>, list or revoke <id>")
+	}
+
+	switch cmd.Args[0] {
+	case "create":
+		name := strings.Join(cmd.Args[1:], " ")
+		token, hash, err := server.NewToken()
+		if err != nil {
+			return err
+		}
+		created, err := s.Db.CreateAPIToken(context.Background(),
+			database.CreateAPITokenParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UserID:    user.ID,
+				Name:      name,
+				TokenHash: hash,
+				Scope:     server.ScopeAPI,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created token %v for %s, it won't be shown again:\n", created.ID, user.Name)
+		fmt.Println(token)
+	case "feed":
+		// a token for feed readers, which can only read /api/feed.rss and .atom
+		if len(cmd.Args) != 1 {
+			return fmt.Errorf("token feed takes no arguments")
+		}
+		token, hash, err := server.NewToken()
+		if err != nil {
+			return err
+		}
+		created, err := s.Db.CreateAPIToken(context.Background(),
+			database.CreateAPITokenParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UserID:    user.ID,
+				Name:      "feed",
+				TokenHash: hash,
+				Scope:     server.ScopeFeed,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created feed token %v for %s, subscribe to\n", created.ID, user.Name)
+		fmt.Printf("http://<host>:8080/api/feed.rss?token=%s\n", token)
+	case "fever":
+		// Fever clients log in with md5("username:password") as their key
+		if len(cmd.Args) != 2 {
+			return fmt.Errorf("token fever takes the password to use in Fever clients")
+		}
+		key := server.FeverKey(user.Name, cmd.Args[1])
+		created, err := s.Db.CreateAPIToken(context.Background(),
+			database.CreateAPITokenParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UserID:    user.ID,
+				Name:      "fever",
+				TokenHash: server.HashToken(key),
+				Scope:     server.ScopeFever,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created Fever token %v, log in as %s with that password\n", created.ID, user.Name)
+	case "greader":
+		if len(cmd.Args) != 2 {
+			return fmt.Errorf("token greader takes the password to use in GReader clients")
+		}
+		key := server.GReaderKey(user.Name, cmd.Args[1])
+		created, err := s.Db.CreateAPIToken(context.Background(),
+			database.CreateAPITokenParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UserID:    user.ID,
+				Name:      "greader",
+				TokenHash: server.HashToken(key),
+				Scope:     server.ScopeGReader,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created GReader token %v, log in as %s with that password\n", created.ID, user.Name)
+	case "list":
+		tokens, err := s.Db.GetAPITokensForUser(context.Background(), user.ID)
+		if err != nil {
+			return err
+		}
+		rows := make([]tokenRow, 0, len(tokens))
+		for _, token := range tokens {
+			rows = append(rows, tokenRow{
+				ID:        token.ID,
+				Name:      token.Name,
+				Scope:     token.Scope,
+				CreatedAt: token.CreatedAt,
+			})
+		}
+		return printRows(s, rows, func() error {
+			for _, token := range rows {
+				fmt.Printf("%v %s (%s, created %v)\n", token.ID, token.Name, token.Scope, token.CreatedAt)
+			}
+			return nil
+		})
+	case "revoke":
+		if len(cmd.Args) != 2 {
+			return fmt.Errorf("token revoke takes the id of the token")
+		}
+		id, err := uuid.Parse(cmd.Args[1])
+		if err != nil {
+			return err
+		}
+		deleted, err := s.Db.DeleteAPIToken(context.Background(),
+			database.DeleteAPITokenParams{
+				ID:     id,
+				UserID: user.ID,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return fmt.Errorf("no token %v for %s", id, user.Name)
+		}
+		fmt.Printf("revoked token %v\n", id)
+	default:
+		return fmt.Errorf("unknown token subcommand %s, expected create, feed, fever, greader, list or revoke", cmd.Args[0])
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/render"
+)
+
+func HandlerStar(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return fmt.Errorf("star takes the id or url of a post, optionally followed by a note")
+	}
+
+	post, err := lookupPost(s, user, cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("could not find post %s: %w", cmd.Args[0], err)
+	}
+	note := strings.Join(cmd.Args[1:], " ")
+
+	err = s.Db.StarPost(context.Background(),
+		database.StarPostParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			CreatedAt: time.Now(),
+			Note:      note,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("starred %s\n", post.Title)
+	return nil
+}
+
+func HandlerUnstar(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("unstar takes one argument: the id or url of a post")
+	}
+
+	post, err := lookupPost(s, user, cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("could not find post %s: %w", cmd.Args[0], err)
+	}
+
+	removed, err := s.Db.UnstarPost(context.Background(),
+		database.UnstarPostParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return fmt.Errorf("%s is not starred", post.Title)
+	}
+
+	fmt.Printf("unstarred %s\n", post.Title)
+	return nil
+}
+
+type starredRow struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	StarredAt   time.Time `json:"starred_at"`
+	Note        string    `json:"note"`
+	Description string    `json:"description" table:"-"`
+}
+
+func HandlerStarred(s *State, cmd Command, user database.User) error {
+	limit, err := parsePostLimit(cmd.Name, cmd.Args)
+	if err != nil {
+		return err
+	}
+
+	posts, err := s.Db.GetStarredPostsForUser(context.Background(),
+		database.GetStarredPostsForUserParams{
+			UserID: user.ID,
+			Limit:  int32(limit),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]starredRow, 0, len(posts))
+	for _, post := range posts {
+		rows = append(rows, starredRow{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			PublishedAt: post.PublishedAt,
+			StarredAt:   post.StarredAt,
+			Note:        post.Note,
+			Description: post.Description,
+		})
+	}
+
+	return printRows(s, rows, func() error {
+		opts := render.OptionsFor(os.Stdout)
+		for _, post := range rows {
+			fmt.Println("*", post.Title)
+			fmt.Println(post.ID)
+			fmt.Println(post.PublishedAt)
+			if post.Note != "" {
+				fmt.Println("note:", post.Note)
+			}
+			opts.BaseURL = post.URL
+			fmt.Println(render.HTML(post.Description, opts))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/dbtest"
+)
+
+// Starred posts outlive any cleanup of old posts, with their notes.
+func TestStarredPostsCantBeDeleted(t *testing.T) {
+	db := dbtest.Open(t)
+	dbtest.MigrateAll(t, db)
+	now := time.Now()
+	userID, feedID, postID := uuid.New(), uuid.New(), uuid.New()
+	dbtest.Exec(t, db, "INSERT INTO users (id, created_at, updated_at, name) VALUES ($1, $2, $2, 'kahya')", userID, now)
+	dbtest.Exec(t, db, "INSERT INTO feeds (id, created_at, updated_at, name, url, user_id) VALUES ($1, $2, $2, 'blog', 'https://example.com/feed', $3)", feedID, now, userID)
+	dbtest.Exec(t, db, "INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid) VALUES ($1, $2, $2, 'A', 'https://example.com/a', '', $2, $3, 'a')", postID, now, feedID)
+
+	err := database.New(db).StarPost(context.Background(),
+		database.StarPostParams{
+			UserID:    userID,
+			PostID:    postID,
+			CreatedAt: now,
+			Note:      "keep",
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec("DELETE FROM posts WHERE id = $1", postID)
+	if err == nil {
+		t.Fatal("deleted a starred post")
+	}
+	var note string
+	err = db.QueryRow("SELECT note FROM stars WHERE post_id = $1", postID).Scan(&note)
+	if err != nil || note != "keep" {
+		t.Errorf("star note = %q, %v", note, err)
+	}
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/tui"
+)
+
+const defaultTUIRefresh = time.Minute
+
+// HandlerTUI opens the interactive reader. It only reads the database, run agg
+// alongside it to fetch new posts.
+func HandlerTUI(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	refresh := fs.Duration("refresh", defaultTUIRefresh, "how often to reload posts from the database")
+	args, err := parseArgs(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("tui takes no arguments besides --refresh")
+	}
+	if *refresh <= 0 {
+		return fmt.Errorf("refresh must be positive")
+	}
+
+	return tui.Run(s.Db, user, *refresh)
+}
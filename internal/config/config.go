@@ -6,11 +6,17 @@ import (
 	"path/filepath"
 )
 
-const configFileName = ".gatorconfig.json"
+const (
+	configFileName = ".gatorconfig.json"
+
+	defaultMaxFeedFailures = 10
+)
 
 type Config struct {
 	DbURL           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	// MaxFeedFailures is how many fetches in a row may fail before a feed is disabled
+	MaxFeedFailures int `json:"max_feed_failures,omitempty"`
 }
 
 func Read() (Config, error) {
@@ -31,6 +37,14 @@ func Read() (Config, error) {
 	return cfg, nil
 }
 
+// FeedFailureLimit returns MaxFeedFailures, or the default if it isn't set.
+func (c *Config) FeedFailureLimit() int {
+	if c.MaxFeedFailures <= 0 {
+		return defaultMaxFeedFailures
+	}
+	return c.MaxFeedFailures
+}
+
 func (c *Config) SetUser(username string) error {
 	c.CurrentUserName = username
 	err := write(c)
@@ -0,0 +1,44 @@
+// Package cursor encodes the position of a post in a listing sorted by
+// published_at and id, newest first, as an opaque token.
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalid = errors.New("invalid cursor")
+
+// Encode makes the cursor for the post published at publishedAt with id.
+// Pages after it start with the next older post. Times are kept to the
+// microsecond, as postgres stores them, which also reaches back to the zero
+// time of posts that were saved without a date.
+func Encode(publishedAt time.Time, id uuid.UUID) string {
+	raw := strconv.FormatInt(publishedAt.UnixMicro(), 10) + "," + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func Decode(token string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalid
+	}
+	micros, rawID, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return time.Time{}, uuid.Nil, ErrInvalid
+	}
+	unix, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalid
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalid
+	}
+	return time.UnixMicro(unix).UTC(), id, nil
+}
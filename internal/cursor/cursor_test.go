@@ -0,0 +1,44 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRoundTrip(t *testing.T) {
+	id := uuid.New()
+	for _, publishedAt := range []time.Time{
+		time.Date(2024, 3, 9, 14, 30, 15, 123456000, time.UTC),
+		time.Unix(0, 0).UTC(),
+		// posts saved without a date before dates were estimated
+		{},
+	} {
+		gotTime, gotID, err := Decode(Encode(publishedAt, id))
+		if err != nil {
+			t.Errorf("Decode(Encode(%v)): %v", publishedAt, err)
+			continue
+		}
+		if !gotTime.Equal(publishedAt) || gotID != id {
+			t.Errorf("Decode(Encode(%v)) = %v %v, want %v %v", publishedAt, gotTime, gotID, publishedAt, id)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, token := range []string{
+		"",
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("12345")),
+		base64.RawURLEncoding.EncodeToString([]byte("soon," + uuid.NewString())),
+		base64.RawURLEncoding.EncodeToString([]byte("12345,not-a-uuid")),
+	} {
+		_, _, err := Decode(token)
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("Decode(%q) = %v, want ErrInvalid", token, err)
+		}
+	}
+}
@@ -7,21 +7,23 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 )
 
 const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows (id, user_id, feed_id)
+    INSERT INTO feed_follows (id, user_id, feed_id, folder)
     VALUES (
         $1,
         $2,
-        $3
+        $3,
+        $4
     )
-    RETURNING id, user_id, feed_id
+    RETURNING id, user_id, feed_id, folder
 ) 
-SELECT inserted_feed_follow.id, inserted_feed_follow.user_id, inserted_feed_follow.feed_id,
+SELECT inserted_feed_follow.id, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.folder,
     feeds.name AS feedname,
     users.name AS username
 FROM inserted_feed_follow
@@ -35,30 +37,38 @@ type CreateFeedFollowParams struct {
 	ID     uuid.UUID
 	UserID uuid.UUID
 	FeedID uuid.UUID
+	Folder string
 }
 
 type CreateFeedFollowRow struct {
 	ID       uuid.UUID
 	UserID   uuid.UUID
 	FeedID   uuid.UUID
+	Folder   string
 	Feedname string
 	Username string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
-	row := q.db.QueryRowContext(ctx, createFeedFollow, arg.ID, arg.UserID, arg.FeedID)
+	row := q.db.QueryRowContext(ctx, createFeedFollow,
+		arg.ID,
+		arg.UserID,
+		arg.FeedID,
+		arg.Folder,
+	)
 	var i CreateFeedFollowRow
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.FeedID,
+		&i.Folder,
 		&i.Feedname,
 		&i.Username,
 	)
 	return i, err
 }
 
-const deleteFeedFollow = `-- name: DeleteFeedFollow :exec
+const deleteFeedFollow = `-- name: DeleteFeedFollow :execrows
 DELETE FROM feed_follows
     WHERE user_id = $1 AND feed_id = $2
 `
@@ -68,9 +78,12 @@ type DeleteFeedFollowParams struct {
 	FeedID uuid.UUID
 }
 
-func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowParams) error {
-	_, err := q.db.ExecContext(ctx, deleteFeedFollow, arg.UserID, arg.FeedID)
-	return err
+func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollow, arg.UserID, arg.FeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
@@ -79,7 +92,9 @@ SELECT feed_follows.id,
     users.name AS username,
     feed_follows.feed_id,
     feeds.name AS feedname,
-    feeds.url AS url
+    feeds.url AS url,
+    feeds.site_url,
+    feed_follows.folder
 FROM feed_follows
 JOIN feeds
 ON feed_follows.feed_id = feeds.id
@@ -95,6 +110,8 @@ type GetFeedFollowsForUserRow struct {
 	FeedID   uuid.UUID
 	Feedname string
 	Url      string
+	SiteUrl  string
+	Folder   string
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -113,6 +130,8 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.FeedID,
 			&i.Feedname,
 			&i.Url,
+			&i.SiteUrl,
+			&i.Folder,
 		); err != nil {
 			return nil, err
 		}
@@ -126,3 +145,78 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const getFollowedFeeds = `-- name: GetFollowedFeeds :many
+SELECT feeds.seq,
+    feeds.id,
+    feeds.name,
+    feeds.url,
+    feeds.site_url,
+    feeds.last_fetched_at,
+    feed_follows.folder
+FROM feed_follows
+JOIN feeds
+ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.seq
+`
+
+type GetFollowedFeedsRow struct {
+	Seq           int64
+	ID            uuid.UUID
+	Name          string
+	Url           string
+	SiteUrl       string
+	LastFetchedAt sql.NullTime
+	Folder        string
+}
+
+// The feeds a user follows in the order of their seq, which the client APIs
+// identify them by.
+func (q *Queries) GetFollowedFeeds(ctx context.Context, userID uuid.UUID) ([]GetFollowedFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedFeeds, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFollowedFeedsRow
+	for rows.Next() {
+		var i GetFollowedFeedsRow
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.SiteUrl,
+			&i.LastFetchedAt,
+			&i.Folder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setFeedFollowFolder = `-- name: SetFeedFollowFolder :exec
+UPDATE feed_follows
+SET folder = $3
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowFolderParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+	Folder string
+}
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg SetFeedFollowFolderParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFollowFolder, arg.UserID, arg.FeedID, arg.Folder)
+	return err
+}
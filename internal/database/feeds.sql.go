@@ -13,6 +13,70 @@ import (
 	"github.com/google/uuid"
 )
 
+const claimFeedsToFetch = `-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET next_fetch_at = $1
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE NOT disabled
+        AND (next_fetch_at IS NULL OR next_fetch_at <= $2)
+    ORDER BY next_fetch_at ASC NULLS FIRST
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, poll_interval_seconds, update_hint_seconds, failure_count, last_error, last_error_at, disabled, seq, site_url
+`
+
+type ClaimFeedsToFetchParams struct {
+	LeaseUntil sql.NullTime
+	Now        sql.NullTime
+	BatchSize  int32
+}
+
+// Pushes next_fetch_at of the claimed feeds out to lease_until, so other agg
+// processes skip them until MarkFeedFetched sets the real next fetch time.
+func (q *Queries) ClaimFeedsToFetch(ctx context.Context, arg ClaimFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimFeedsToFetch, arg.LeaseUntil, arg.Now, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.PollIntervalSeconds,
+			&i.UpdateHintSeconds,
+			&i.FailureCount,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.Disabled,
+			&i.Seq,
+			&i.SiteUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, last_fetched_at, user_id)
 VALUES (
@@ -24,7 +88,7 @@ VALUES (
     $6,
     $7
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, poll_interval_seconds, update_hint_seconds, failure_count, last_error, last_error_at, disabled, seq, site_url
 `
 
 type CreateFeedParams struct {
@@ -56,12 +120,42 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.PollIntervalSeconds,
+		&i.UpdateHintSeconds,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Disabled,
+		&i.Seq,
+		&i.SiteUrl,
 	)
 	return i, err
 }
 
+const enableFeed = `-- name: EnableFeed :exec
+UPDATE feeds
+SET disabled = FALSE,
+    failure_count = 0,
+    next_fetch_at = NULL,
+    updated_at = $2
+WHERE id = $1
+`
+
+type EnableFeedParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) EnableFeed(ctx context.Context, arg EnableFeedParams) error {
+	_, err := q.db.ExecContext(ctx, enableFeed, arg.ID, arg.UpdatedAt)
+	return err
+}
+
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, poll_interval_seconds, update_hint_seconds, failure_count, last_error, last_error_at, disabled, seq, site_url FROM feeds
 WHERE url = $1
 `
 
@@ -76,6 +170,17 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.PollIntervalSeconds,
+		&i.UpdateHintSeconds,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Disabled,
+		&i.Seq,
+		&i.SiteUrl,
 	)
 	return i, err
 }
@@ -88,6 +193,9 @@ SELECT feeds.id,
     feeds.url, 
     feeds.user_id, 
     feeds.last_fetched_at,
+    feeds.failure_count,
+    feeds.last_error,
+    feeds.disabled,
     users.name AS username
 FROM feeds
 JOIN users
@@ -102,6 +210,9 @@ type GetFeedsRow struct {
 	Url           string
 	UserID        uuid.UUID
 	LastFetchedAt sql.NullTime
+	FailureCount  int32
+	LastError     string
+	Disabled      bool
 	Username      string
 }
 
@@ -122,6 +233,9 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.FailureCount,
+			&i.LastError,
+			&i.Disabled,
 			&i.Username,
 		); err != nil {
 			return nil, err
@@ -137,41 +251,106 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1
+const markFeedFetched = `-- name: MarkFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = $2,
+    updated_at = $3,
+    etag = $4,
+    last_modified = $5,
+    next_fetch_at = $6,
+    update_hint_seconds = $7,
+    failure_count = 0
+WHERE ID = $1
+`
+
+type MarkFeedFetchedParams struct {
+	ID                uuid.UUID
+	LastFetchedAt     sql.NullTime
+	UpdatedAt         time.Time
+	Etag              string
+	LastModified      string
+	NextFetchAt       sql.NullTime
+	UpdateHintSeconds int32
+}
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetched,
+		arg.ID,
+		arg.LastFetchedAt,
+		arg.UpdatedAt,
+		arg.Etag,
+		arg.LastModified,
+		arg.NextFetchAt,
+		arg.UpdateHintSeconds,
+	)
+	return err
+}
+
+const recordFeedError = `-- name: RecordFeedError :exec
+UPDATE feeds
+SET failure_count = $2,
+    last_error = $3,
+    last_error_at = $4,
+    next_fetch_at = $5,
+    disabled = $6,
+    updated_at = $7
+WHERE id = $1
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
-	var i Feed
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Url,
-		&i.UserID,
-		&i.LastFetchedAt,
+type RecordFeedErrorParams struct {
+	ID           uuid.UUID
+	FailureCount int32
+	LastError    string
+	LastErrorAt  sql.NullTime
+	NextFetchAt  sql.NullTime
+	Disabled     bool
+	UpdatedAt    time.Time
+}
+
+func (q *Queries) RecordFeedError(ctx context.Context, arg RecordFeedErrorParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedError,
+		arg.ID,
+		arg.FailureCount,
+		arg.LastError,
+		arg.LastErrorAt,
+		arg.NextFetchAt,
+		arg.Disabled,
+		arg.UpdatedAt,
 	)
-	return i, err
+	return err
 }
 
-const markFeedFetched = `-- name: MarkFeedFetched :exec
+const setFeedPollInterval = `-- name: SetFeedPollInterval :exec
 UPDATE feeds
-SET last_fetched_at = $2,
+SET poll_interval_seconds = $2,
+    next_fetch_at = NULL,
     updated_at = $3
-WHERE ID = $1
+WHERE id = $1
 `
 
-type MarkFeedFetchedParams struct {
-	ID            uuid.UUID
-	LastFetchedAt sql.NullTime
-	UpdatedAt     time.Time
+type SetFeedPollIntervalParams struct {
+	ID                  uuid.UUID
+	PollIntervalSeconds sql.NullInt32
+	UpdatedAt           time.Time
 }
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.ID, arg.LastFetchedAt, arg.UpdatedAt)
+func (q *Queries) SetFeedPollInterval(ctx context.Context, arg SetFeedPollIntervalParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedPollInterval, arg.ID, arg.PollIntervalSeconds, arg.UpdatedAt)
+	return err
+}
+
+const setFeedSiteURL = `-- name: SetFeedSiteURL :exec
+UPDATE feeds
+SET site_url = $2
+WHERE id = $1
+`
+
+type SetFeedSiteURLParams struct {
+	ID      uuid.UUID
+	SiteUrl string
+}
+
+func (q *Queries) SetFeedSiteURL(ctx context.Context, arg SetFeedSiteURLParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedSiteURL, arg.ID, arg.SiteUrl)
 	return err
 }
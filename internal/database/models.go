@@ -11,31 +11,70 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiToken struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+	TokenHash string
+	Scope     string
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	Etag                string
+	LastModified        string
+	NextFetchAt         sql.NullTime
+	PollIntervalSeconds sql.NullInt32
+	UpdateHintSeconds   int32
+	FailureCount        int32
+	LastError           string
+	LastErrorAt         sql.NullTime
+	Disabled            bool
+	Seq                 int64
+	SiteUrl             string
 }
 
 type FeedFollow struct {
 	ID     uuid.UUID
 	UserID uuid.UUID
 	FeedID uuid.UUID
+	Folder string
 }
 
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description string
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          string
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	PublishedAtEstimated bool
+	Guid                 sql.NullString
+	SearchVector         interface{}
+	Seq                  int64
+}
+
+type PostState struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	Read   bool
+	ReadAt sql.NullTime
+}
+
+type Star struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	Note      string
 }
 
 type User struct {
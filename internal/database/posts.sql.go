@@ -13,44 +13,111 @@ import (
 	"github.com/google/uuid"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5,
-    $6, 
-    $7,
-    $8
-)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+const adoptPostGUID = `-- name: AdoptPostGUID :exec
+UPDATE posts
+SET guid = $3
+WHERE feed_id = $1
+    AND url = $2
+    AND guid IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM posts AS adopted
+        WHERE adopted.feed_id = $1 AND adopted.guid = $3
+    )
 `
 
-type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description string
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+type AdoptPostGUIDParams struct {
+	FeedID uuid.UUID
+	Url    string
+	Guid   sql.NullString
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.ID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
-		arg.FeedID,
+// Gives a post stored before posts had guids the guid of the item at its url,
+// so the upsert that follows updates it instead of storing it again.
+func (q *Queries) AdoptPostGUID(ctx context.Context, arg AdoptPostGUIDParams) error {
+	_, err := q.db.ExecContext(ctx, adoptPostGUID, arg.FeedID, arg.Url, arg.Guid)
+	return err
+}
+
+const getPostByID = `-- name: GetPostByID :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_estimated, posts.guid, posts.seq
+FROM posts
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id
+WHERE posts.id = $1 AND feed_follows.user_id = $2
+`
+
+type GetPostByIDParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+type GetPostByIDRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          string
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	PublishedAtEstimated bool
+	Guid                 sql.NullString
+	Seq                  int64
+}
+
+// The post with id, if it is in a feed the user follows.
+func (q *Queries) GetPostByID(ctx context.Context, arg GetPostByIDParams) (GetPostByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostByID, arg.ID, arg.UserID)
+	var i GetPostByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.PublishedAtEstimated,
+		&i.Guid,
+		&i.Seq,
 	)
-	var i Post
+	return i, err
+}
+
+const getPostByURL = `-- name: GetPostByURL :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_estimated, posts.guid, posts.seq
+FROM posts
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id
+WHERE posts.url = $1 AND feed_follows.user_id = $2
+ORDER BY posts.published_at DESC
+LIMIT 1
+`
+
+type GetPostByURLParams struct {
+	Url    string
+	UserID uuid.UUID
+}
+
+type GetPostByURLRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          string
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	PublishedAtEstimated bool
+	Guid                 sql.NullString
+	Seq                  int64
+}
+
+// The latest post at url in a feed the user follows.
+func (q *Queries) GetPostByURL(ctx context.Context, arg GetPostByURLParams) (GetPostByURLRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostByURL, arg.Url, arg.UserID)
+	var i GetPostByURLRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
@@ -60,44 +127,134 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.PublishedAtEstimated,
+		&i.Guid,
+		&i.Seq,
 	)
 	return i, err
 }
 
+const getPostDatesForFeed = `-- name: GetPostDatesForFeed :many
+SELECT published_at FROM posts
+WHERE feed_id = $1 AND NOT published_at_estimated
+ORDER BY published_at DESC
+LIMIT $2
+`
+
+type GetPostDatesForFeedParams struct {
+	FeedID uuid.UUID
+	Limit  int32
+}
+
+func (q *Queries) GetPostDatesForFeed(ctx context.Context, arg GetPostDatesForFeedParams) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, getPostDatesForFeed, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var published_at time.Time
+		if err := rows.Scan(&published_at); err != nil {
+			return nil, err
+		}
+		items = append(items, published_at)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, title, posts.url, description, published_at, feed_id, feeds.id, feeds.created_at, feeds.updated_at, name, feeds.url, user_id, last_fetched_at FROM posts
+SELECT posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.published_at_estimated,
+    posts.guid,
+    posts.seq,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    COALESCE(post_states.read, FALSE)::boolean AS read,
+    (stars.post_id IS NOT NULL)::boolean AS starred
+FROM posts
 JOIN feeds
 ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+LEFT JOIN stars
+ON stars.post_id = posts.id AND stars.user_id = $1
+WHERE ($2::boolean OR NOT COALESCE(post_states.read, FALSE))
+    AND ($3::uuid IS NULL OR posts.feed_id = $3::uuid)
+    AND ($4::timestamp IS NULL OR posts.published_at >= $4::timestamp)
+    AND ($5::timestamp IS NULL OR posts.published_at < $5::timestamp)
+    AND ($6::text = ''
+        OR strpos(lower(posts.title), lower($6::text)) > 0
+        OR strpos(lower(posts.description), lower($6::text)) > 0)
+    AND ($7::timestamp IS NULL
+        OR (posts.published_at, posts.id) < ($7::timestamp, $8::uuid))
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT $9
+OFFSET $10
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID           uuid.UUID
+	IncludeRead      bool
+	FeedID           uuid.NullUUID
+	Since            sql.NullTime
+	Until            sql.NullTime
+	Match            string
+	AfterPublishedAt sql.NullTime
+	AfterID          uuid.NullUUID
+	Limit            int32
+	Offset           int32
 }
 
 type GetPostsForUserRow struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Title         string
-	Url           string
-	Description   string
-	PublishedAt   time.Time
-	FeedID        uuid.UUID
-	ID_2          uuid.UUID
-	CreatedAt_2   time.Time
-	UpdatedAt_2   time.Time
-	Name          string
-	Url_2         string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          string
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	PublishedAtEstimated bool
+	Guid                 sql.NullString
+	Seq                  int64
+	FeedName             string
+	FeedUrl              string
+	Read                 bool
+	Starred              bool
 }
 
+// Posts from every feed the user follows, whoever added it. The feed, time
+// range and match filters are skipped when null or empty. Pages continue
+// after the post at (after_published_at, after_id).
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.IncludeRead,
+		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.Match,
+		arg.AfterPublishedAt,
+		arg.AfterID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -114,13 +271,13 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
-			&i.ID_2,
-			&i.CreatedAt_2,
-			&i.UpdatedAt_2,
-			&i.Name,
-			&i.Url_2,
-			&i.UserID,
-			&i.LastFetchedAt,
+			&i.PublishedAtEstimated,
+			&i.Guid,
+			&i.Seq,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.Read,
+			&i.Starred,
 		); err != nil {
 			return nil, err
 		}
@@ -134,3 +291,129 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+SELECT posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(posts.search_vector, query)::real AS rank,
+    ts_headline('english', translate(posts.title || ' ' || posts.description, chr(2) || chr(3), ''), query,
+        'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=2, MaxWords=30, MinWords=10')::text AS snippet
+FROM posts
+JOIN feeds
+ON posts.feed_id = feeds.id
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id,
+websearch_to_tsquery('english', $1) query
+WHERE feed_follows.user_id = $2
+    AND posts.search_vector @@ query
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT $3
+`
+
+type SearchPostsForUserParams struct {
+	Query  string
+	UserID uuid.UUID
+	Limit  int32
+}
+
+type SearchPostsForUserRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	PublishedAt time.Time
+	FeedName    string
+	Rank        float32
+	Snippet     string
+}
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser, arg.Query, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsForUserRow
+	for rows.Next() {
+		var i SearchPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertPost = `-- name: UpsertPost :one
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, published_at_estimated, guid)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
+)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    updated_at = EXCLUDED.updated_at
+WHERE posts.title <> EXCLUDED.title
+    OR posts.url <> EXCLUDED.url
+    OR posts.description <> EXCLUDED.description
+RETURNING (xmax = 0)::boolean AS inserted
+`
+
+type UpsertPostParams struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          string
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	PublishedAtEstimated bool
+	Guid                 sql.NullString
+}
+
+// Inserts a post, or updates it if the publisher changed it since it was
+// stored. Returns no rows when the post is already stored unchanged.
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.PublishedAtEstimated,
+		arg.Guid,
+	)
+	var inserted bool
+	err := row.Scan(&inserted)
+	return inserted, err
+}
@@ -0,0 +1,107 @@
+// Package dbtest gives tests their own postgres schema to run queries
+// against. Tests using it are skipped unless GATOR_TEST_DB_URL names a
+// database they may create schemas in.
+package dbtest
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+// Open connects to a new, empty schema, which is dropped when the test ends.
+// Migrate it before use.
+func Open(t testing.TB) *sql.DB {
+	t.Helper()
+	dbURL := os.Getenv("GATOR_TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("GATOR_TEST_DB_URL is not set")
+	}
+
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	schema := "gator_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if err != nil {
+			t.Errorf("dropping %s: %v", schema, err)
+		}
+	})
+
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	db, err := sql.Open("postgres", u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// Migrate runs the up migrations in sql/schema numbered after from, up to and
+// including to.
+func Migrate(t testing.TB, db *sql.DB, from, to int) {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "..", "sql", "schema", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		number, _, _ := strings.Cut(filepath.Base(file), "_")
+		version, err := strconv.Atoi(number)
+		if err != nil || version <= from || version > to {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, up, _ := strings.Cut(string(data), "-- +goose Up")
+		up, _, _ = strings.Cut(up, "-- +goose Down")
+		_, err = db.Exec(up)
+		if err != nil {
+			t.Fatalf("%s: %v", filepath.Base(file), err)
+		}
+	}
+}
+
+// MigrateAll runs every up migration.
+func MigrateAll(t testing.TB, db *sql.DB) {
+	t.Helper()
+	Migrate(t, db, 0, math.MaxInt)
+}
+
+// Exec runs a statement that setting up a test can't fail.
+func Exec(t testing.TB, db *sql.DB, query string, args ...any) {
+	t.Helper()
+	_, err := db.Exec(query, args...)
+	if err != nil {
+		t.Fatal(fmt.Errorf("%s: %w", query, err))
+	}
+}
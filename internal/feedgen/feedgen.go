@@ -0,0 +1,217 @@
+// Package feedgen writes posts back out as RSS 2.0 and Atom 1.0 feeds.
+package feedgen
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Feed is a feed to write, made from posts of any number of source feeds.
+type Feed struct {
+	// ID identifies the feed for Atom, it should never change
+	ID    string
+	Title string
+	// Link is the site the feed belongs to and Self where the feed itself is
+	// published, both optional
+	Link        string
+	Self        string
+	Description string
+	Author      string
+	// Updated is when the feed last changed, the time it is written if zero
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is a post. ID is its guid, so it should stay the same every time the
+// feed is written.
+type Item struct {
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	Published   time.Time
+	Updated     time.Time
+	Source      Source
+}
+
+// Source is the feed an item was originally published in.
+type Source struct {
+	Title string
+	URL   string
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Self          *atomLink `xml:"atom:link,omitempty"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link,omitempty"`
+	Description string    `xml:"description"`
+	GUID        rssGUID   `xml:"guid"`
+	PubDate     string    `xml:"pubDate"`
+	Source      rssSource `xml:"source"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	URL   string `xml:"url,attr"`
+	Title string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   atomText   `xml:"summary"`
+	Source    atomSource `xml:"source"`
+}
+
+type atomSource struct {
+	ID    string     `xml:"id"`
+	Title string     `xml:"title"`
+	Links []atomLink `xml:"link"`
+}
+
+// WriteRSS writes feed as RSS 2.0. Items name their feed of origin in a
+// <source> element.
+func WriteRSS(w io.Writer, feed Feed) error {
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	doc := rss{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   feed.Description,
+			LastBuildDate: updated.UTC().Format(time.RFC1123Z),
+			Generator:     "gator",
+			Items:         make([]rssItem, 0, len(feed.Items)),
+		},
+	}
+	if feed.Self != "" {
+		doc.Channel.Self = &atomLink{Href: feed.Self, Rel: "self", Type: "application/rss+xml"}
+	}
+
+	for _, item := range feed.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			GUID:        rssGUID{Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Source:      rssSource{URL: item.Source.URL, Title: item.Source.Title},
+		})
+	}
+	return write(w, doc)
+}
+
+// WriteAtom writes feed as Atom 1.0. Items name their feed of origin in a
+// <source> element.
+func WriteAtom(w io.Writer, feed Feed) error {
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	doc := atomFeed{
+		ID:      feed.ID,
+		Title:   feed.Title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Author:  atomPerson{Name: feed.Author},
+		Entries: make([]atomEntry, 0, len(feed.Items)),
+	}
+	if feed.Self != "" {
+		doc.Links = append(doc.Links, atomLink{Href: feed.Self, Rel: "self", Type: "application/atom+xml"})
+	}
+	if feed.Link != "" {
+		doc.Links = append(doc.Links, atomLink{Href: feed.Link, Rel: "alternate", Type: "text/html"})
+	}
+
+	for _, item := range feed.Items {
+		updated := item.Updated
+		if updated.Before(item.Published) {
+			updated = item.Published
+		}
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   updated.UTC().Format(time.RFC3339),
+			Summary:   atomText{Type: "html", Value: item.Description},
+			Source: atomSource{
+				ID:    item.Source.URL,
+				Title: item.Source.Title,
+				Links: []atomLink{{Href: item.Source.URL, Rel: "self"}},
+			},
+		}
+		if item.Link != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.Link, Rel: "alternate"})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return write(w, doc)
+}
+
+// write encodes doc as an indented xml document, like opml.Write.
+func write(w io.Writer, doc any) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,107 @@
+package feedgen
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+)
+
+func TestEmptyUserFeed(t *testing.T) {
+	user := database.User{ID: uuid.New(), Name: "kahya"}
+	feed := UserFeed(user, nil, "https://example.com/feed.rss?token=x")
+	if feed.Link != "https://example.com/" {
+		t.Errorf("Link = %q", feed.Link)
+	}
+
+	var rss strings.Builder
+	err := WriteRSS(&rss, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<link>https://example.com/</link>",
+		`<atom:link href="https://example.com/feed.rss?token=x" rel="self"`,
+		time.Now().UTC().Format("02 Jan 2006"),
+	} {
+		if !strings.Contains(rss.String(), want) {
+			t.Errorf("%q missing from\n%s", want, rss.String())
+		}
+	}
+
+	var atom strings.Builder
+	err = WriteAtom(&atom, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(atom.String(), "0001-01-01") {
+		t.Errorf("zero updated time in\n%s", atom.String())
+	}
+}
+
+func TestUserFeedItems(t *testing.T) {
+	user := database.User{ID: uuid.New(), Name: "kahya"}
+	postID := uuid.New()
+	posts := []database.GetPostsForUserRow{{
+		ID:          postID,
+		Title:       "Fish & Chips",
+		Url:         "https://example.com/fish",
+		Description: "<p>Hi</p>",
+		PublishedAt: time.Date(2024, 3, 2, 10, 4, 5, 0, time.FixedZone("CET", 3600)),
+		UpdatedAt:   time.Date(2024, 3, 3, 8, 0, 0, 0, time.UTC),
+		FeedName:    "Blog",
+		FeedUrl:     "https://example.com/feed",
+	}}
+	feed := UserFeed(user, posts, "https://gator.example/api/feed.rss")
+
+	var rss, again strings.Builder
+	err := WriteRSS(&rss, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = WriteRSS(&again, UserFeed(user, posts, "https://gator.example/api/feed.rss"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// readers tell posts apart by their guid, so writing a feed again must
+	// not change anything
+	if rss.String() != again.String() {
+		t.Errorf("feed changed when written again:\n%s\n%s", rss.String(), again.String())
+	}
+	for _, want := range []string{
+		"<link>https://gator.example/</link>",
+		`<atom:link href="https://gator.example/api/feed.rss" rel="self" type="application/rss+xml">`,
+		"<lastBuildDate>Sun, 03 Mar 2024 08:00:00 +0000</lastBuildDate>",
+		"<title>Fish &amp; Chips</title>",
+		"<link>https://example.com/fish</link>",
+		`<guid isPermaLink="false">urn:uuid:` + postID.String() + "</guid>",
+		"<pubDate>Sat, 02 Mar 2024 09:04:05 +0000</pubDate>",
+		`<source url="https://example.com/feed">Blog</source>`,
+	} {
+		if !strings.Contains(rss.String(), want) {
+			t.Errorf("%q missing from\n%s", want, rss.String())
+		}
+	}
+
+	var atom strings.Builder
+	err = WriteAtom(&atom, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<id>urn:uuid:" + user.ID.String() + "</id>",
+		`<link href="https://gator.example/api/feed.rss" rel="self" type="application/atom+xml"></link>`,
+		`<link href="https://gator.example/" rel="alternate" type="text/html"></link>`,
+		"<updated>2024-03-03T08:00:00Z</updated>",
+		"<id>urn:uuid:" + postID.String() + "</id>",
+		`<link href="https://example.com/fish" rel="alternate"></link>`,
+		"<published>2024-03-02T09:04:05Z</published>",
+		`<summary type="html">&lt;p&gt;Hi&lt;/p&gt;</summary>`,
+	} {
+		if !strings.Contains(atom.String(), want) {
+			t.Errorf("%q missing from\n%s", want, atom.String())
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package feedgen
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/quanchobi/gator/internal/database"
+)
+
+// UserFeed merges a user's posts into one feed. self is where the feed itself
+// can be fetched from, if anywhere, and the root of its host is used as the
+// feed's site.
+func UserFeed(user database.User, posts []database.GetPostsForUserRow, self string) Feed {
+	feed := Feed{
+		ID:          "urn:uuid:" + user.ID.String(),
+		Title:       fmt.Sprintf("%s's gator feed", user.Name),
+		Self:        self,
+		Description: fmt.Sprintf("Posts from the feeds %s follows", user.Name),
+		Author:      user.Name,
+		Items:       make([]Item, 0, len(posts)),
+	}
+	if u, err := url.Parse(self); err == nil && u.Host != "" {
+		feed.Link = (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}).String()
+	}
+	for _, post := range posts {
+		if post.UpdatedAt.After(feed.Updated) {
+			feed.Updated = post.UpdatedAt
+		}
+		feed.Items = append(feed.Items, Item{
+			ID:          "urn:uuid:" + post.ID.String(),
+			Title:       post.Title,
+			Link:        post.Url,
+			Description: post.Description,
+			Published:   post.PublishedAt,
+			Updated:     post.UpdatedAt,
+			Source: Source{
+				Title: post.FeedName,
+				URL:   post.FeedUrl,
+			},
+		})
+	}
+	return feed
+}
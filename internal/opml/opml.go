@@ -0,0 +1,166 @@
+package opml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+// OPML is an OPML 1.0 or 2.0 subscription list.
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+	OwnerName   string `xml:"ownerName,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline is either a feed, when XMLURL is set, or a folder of outlines.
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Category string    `xml:"category,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Subscription is a feed from an OPML document, with the folders it was
+// nested in joined by "/".
+type Subscription struct {
+	Name    string
+	URL     string
+	SiteURL string
+	Folder  string
+}
+
+func Parse(r io.Reader) (*OPML, error) {
+	var doc OPML
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	err := decoder.Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Subscriptions flattens the outline tree into the feeds it contains.
+func (o *OPML) Subscriptions() []Subscription {
+	var subs []Subscription
+	for _, outline := range o.Body.Outlines {
+		subs = outline.collect(subs, nil)
+	}
+	return subs
+}
+
+func (o Outline) collect(subs []Subscription, folders []string) []Subscription {
+	if o.XMLURL != "" {
+		folder := strings.Join(folders, "/")
+		if folder == "" {
+			folder = categoryFolder(o.Category)
+		}
+		return append(subs, Subscription{
+			Name:    o.name(),
+			URL:     strings.TrimSpace(o.XMLURL),
+			SiteURL: o.HTMLURL,
+			Folder:  folder,
+		})
+	}
+
+	if name := o.name(); name != "" {
+		folders = append(folders, name)
+	}
+	for _, child := range o.Outlines {
+		subs = child.collect(subs, folders)
+	}
+	return subs
+}
+
+func (o Outline) name() string {
+	if o.Title != "" {
+		return strings.TrimSpace(o.Title)
+	}
+	return strings.TrimSpace(o.Text)
+}
+
+// categoryFolder turns the first entry of an OPML 2.0 category attribute, such
+// as "/Tech/Go,/Programming", into a folder.
+func categoryFolder(category string) string {
+	first, _, _ := strings.Cut(category, ",")
+	return strings.Trim(strings.TrimSpace(first), "/")
+}
+
+// New builds an OPML 2.0 document from subs, nesting each feed in outlines for
+// its folders.
+func New(title string, subs []Subscription) *OPML {
+	doc := &OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, sub := range subs {
+		var folders []string
+		for _, folder := range strings.Split(sub.Folder, "/") {
+			if folder = strings.TrimSpace(folder); folder != "" {
+				folders = append(folders, folder)
+			}
+		}
+		insert(&doc.Body.Outlines, folders, Outline{
+			Text:    sub.Name,
+			Title:   sub.Name,
+			Type:    "rss",
+			XMLURL:  sub.URL,
+			HTMLURL: sub.SiteURL,
+		})
+	}
+	return doc
+}
+
+func insert(outlines *[]Outline, folders []string, feed Outline) {
+	if len(folders) == 0 {
+		*outlines = append(*outlines, feed)
+		return
+	}
+
+	for i, outline := range *outlines {
+		if outline.XMLURL == "" && outline.Text == folders[0] {
+			insert(&(*outlines)[i].Outlines, folders[1:], feed)
+			return
+		}
+	}
+	*outlines = append(*outlines, Outline{
+		Text:  folders[0],
+		Title: folders[0],
+	})
+	insert(&(*outlines)[len(*outlines)-1].Outlines, folders[1:], feed)
+}
+
+// Write encodes o as an indented xml document.
+func (o *OPML) Write(w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(o)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
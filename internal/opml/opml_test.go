@@ -0,0 +1,76 @@
+package opml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubscriptions(t *testing.T) {
+	doc, err := Parse(strings.NewReader(`<?xml version="1.0"?>
+<opml version="1.0">
+  <head><title>subs</title></head>
+  <body>
+    <outline text="Tech">
+      <outline text="Go">
+        <outline text="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom" htmlUrl="https://go.dev/blog"/>
+      </outline>
+      <outline text="text" title="  Titled  " xmlUrl=" https://example.com/rss "/>
+    </outline>
+    <outline text="Loose" xmlUrl="https://loose.example/feed"/>
+    <outline text="Categorised" xmlUrl="https://cat.example/feed" category="/News/World,/Other"/>
+    <outline text="Empty folder"/>
+  </body>
+</opml>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Subscription{
+		{Name: "Go Blog", URL: "https://go.dev/blog/feed.atom", SiteURL: "https://go.dev/blog", Folder: "Tech/Go"},
+		{Name: "Titled", URL: "https://example.com/rss", Folder: "Tech"},
+		{Name: "Loose", URL: "https://loose.example/feed"},
+		{Name: "Categorised", URL: "https://cat.example/feed", Folder: "News/World"},
+	}
+	if got := doc.Subscriptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Subscriptions() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("not xml at all"))
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Go Blog", URL: "https://go.dev/blog/feed.atom", SiteURL: "https://go.dev/blog", Folder: "Tech/Go"},
+		{Name: "Fish & Chips", URL: "https://example.com/rss?a=1&b=2", Folder: "Tech"},
+		{Name: "Other Go", URL: "https://other.example/feed", Folder: " Tech / Go "},
+		{Name: "Loose", URL: "https://loose.example/feed", SiteURL: "https://loose.example/"},
+	}
+
+	var b strings.Builder
+	err := New("subs", subs).Write(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `htmlUrl="https://go.dev/blog"`) {
+		t.Errorf("htmlUrl missing from\n%s", b.String())
+	}
+
+	doc, err := Parse(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != "2.0" || doc.Head.Title != "subs" {
+		t.Errorf("head = %q %+v", doc.Version, doc.Head)
+	}
+	// folders are merged, so feeds of a folder come back together
+	want := []Subscription{subs[0], {Name: "Other Go", URL: "https://other.example/feed", Folder: "Tech/Go"}, subs[1], subs[3]}
+	if got := doc.Subscriptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Subscriptions() =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+type AtomFeed struct {
+	Title    AtomText    `xml:"title"`
+	Subtitle AtomText    `xml:"subtitle"`
+	Link     []AtomLink  `xml:"link"`
+	Entry    []AtomEntry `xml:"entry"`
+	Syndication
+}
+
+type AtomEntry struct {
+	ID        string     `xml:"id"`
+	Title     AtomText   `xml:"title"`
+	Link      []AtomLink `xml:"link"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Summary   AtomText   `xml:"summary"`
+	Content   AtomText   `xml:"content"`
+	Author    []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// AtomText is an atom text construct. xhtml content is kept as markup, text
+// and html content arrive as character data.
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+func parseAtom(body []byte) (*Feed, error) {
+	var atom AtomFeed
+	err := xml.Unmarshal(body, &atom)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       atom.Title.String(),
+		Link:        alternateLink(atom.Link),
+		Description: atom.Subtitle.String(),
+		UpdateHint:  atom.Syndication.Interval(),
+	}
+	for _, entry := range atom.Entry {
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+		var names []string
+		for _, author := range entry.Author {
+			if author.Name != "" {
+				names = append(names, author.Name)
+			}
+		}
+		feed.Items = append(feed.Items, Item{
+			ID:          strings.TrimSpace(entry.ID),
+			Title:       entry.Title.String(),
+			Link:        alternateLink(entry.Link),
+			Description: description,
+			PubDate:     strings.TrimSpace(pubDate),
+			Author:      strings.Join(names, ", "),
+		})
+	}
+	return feed, nil
+}
+
+// alternateLink picks the rel="alternate" link, which is also the default when
+// rel is omitted. Falls back to the first link if there is no alternate.
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
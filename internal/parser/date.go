@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// zoneOffsets maps the zone abbreviations feeds commonly use to numeric offsets.
+// time.Parse only knows the offset of abbreviations for the local zone and
+// silently treats every other one as UTC, so they are swapped out beforehand.
+var zoneOffsets = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"WET":  "+0000",
+	"WEST": "+0100",
+	"BST":  "+0100",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"MET":  "+0100",
+	"MEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+	"MSK":  "+0300",
+	"IST":  "+0530",
+	"JST":  "+0900",
+	"KST":  "+0900",
+	"AWST": "+0800",
+	"ACST": "+0930",
+	"AEST": "+1000",
+	"AEDT": "+1100",
+	"NZST": "+1200",
+	"NZDT": "+1300",
+	"AST":  "-0400",
+	"ADT":  "-0300",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"AKST": "-0900",
+	"AKDT": "-0800",
+	"HST":  "-1000",
+}
+
+var weekdays = map[string]bool{
+	"mon": true, "tue": true, "tues": true, "wed": true, "thu": true, "thur": true, "thurs": true, "fri": true, "sat": true, "sun": true,
+	"monday": true, "tuesday": true, "wednesday": true, "thursday": true, "friday": true, "saturday": true, "sunday": true,
+}
+
+// dateLayouts are tried in order against a normalized date, see normalizeDate.
+var dateLayouts = buildDateLayouts()
+
+func buildDateLayouts() []string {
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05-0700",
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04-0700",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05 -0700",
+		"2006-01-02 15:04:05 -07:00",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04 -0700",
+		"2006-01-02 15:04",
+		"20060102T150405Z0700",
+		"2006-01-02",
+		"2006/01/02 15:04:05",
+		"2006/01/02",
+		// ctime and unix date, once the weekday is gone
+		"Jan 2 15:04:05 2006",
+		"Jan 2 15:04:05 -0700 2006",
+	}
+
+	// RFC 822/1123 and the many ways publishers get them wrong: single digit
+	// days, full month names, two digit years, no seconds, missing zones and
+	// month-first ordering.
+	zones := []string{" -0700", " -07:00", ""}
+	for _, month := range []string{"Jan", "January"} {
+		for _, year := range []string{"2006", "06"} {
+			for _, clock := range []string{"15:04:05", "15:04"} {
+				for _, zone := range zones {
+					layouts = append(layouts,
+						"2 "+month+" "+year+" "+clock+zone,
+						month+" 2 "+year+" "+clock+zone,
+					)
+				}
+			}
+			layouts = append(layouts,
+				"2 "+month+" "+year,
+				month+" 2 "+year,
+			)
+		}
+	}
+	return layouts
+}
+
+// ParseDate parses the publication dates found in RSS, Atom, RDF and JSON
+// feeds. The result is always in UTC so posts from different feeds sort
+// correctly once stored.
+func ParseDate(value string) (time.Time, error) {
+	normalized := normalizeDate(value)
+	if normalized == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, normalized)
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format: %q", value)
+}
+
+// normalizeDate collapses whitespace and commas, drops the weekday (which is
+// frequently wrong anyway) and any trailing comment such as "(UTC)", and
+// rewrites zone abbreviations as numeric offsets.
+func normalizeDate(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, "("); i > 0 {
+		value = value[:i]
+	}
+	value = strings.ReplaceAll(value, ",", " ")
+	fields := strings.Fields(value)
+
+	if len(fields) > 0 && weekdays[strings.ToLower(strings.TrimSuffix(fields[0], "."))] {
+		fields = fields[1:]
+	}
+
+	for i, field := range fields {
+		offset, ok := zoneOffsets[strings.ToUpper(field)]
+		if !ok {
+			continue
+		}
+		// "+0000 GMT" style dates already carry an offset
+		if i > 0 && isNumericOffset(fields[i-1]) {
+			fields = append(fields[:i], fields[i+1:]...)
+			break
+		}
+		fields[i] = offset
+		break
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func isNumericOffset(field string) bool {
+	if len(field) != 5 && len(field) != 6 {
+		return false
+	}
+	return field[0] == '+' || field[0] == '-'
+}
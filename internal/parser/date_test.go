@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		// well formed
+		{"Sat, 02 Mar 2024 10:04:05 +0000", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"2024-03-02T10:04:05Z", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"2024-03-02T10:04:05.123+01:00", time.Date(2024, 3, 2, 9, 4, 5, 123000000, time.UTC)},
+		{"2024-03-02", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+
+		// two digit years
+		{"Sat, 02 Mar 24 10:04:05 GMT", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"2 Mar 99 10:04 +0000", time.Date(1999, 3, 2, 10, 4, 0, 0, time.UTC)},
+
+		// missing seconds
+		{"Sat, 02 Mar 2024 10:04 +0200", time.Date(2024, 3, 2, 8, 4, 0, 0, time.UTC)},
+		{"2024-03-02T10:04Z", time.Date(2024, 3, 2, 10, 4, 0, 0, time.UTC)},
+		{"2024-03-02 10:04", time.Date(2024, 3, 2, 10, 4, 0, 0, time.UTC)},
+
+		// zone abbreviations, which time.Parse would treat as UTC
+		{"Sat, 02 Mar 2024 10:04:05 EST", time.Date(2024, 3, 2, 15, 4, 5, 0, time.UTC)},
+		{"Sat, 02 Mar 2024 10:04:05 pdt", time.Date(2024, 3, 2, 17, 4, 5, 0, time.UTC)},
+		{"Sat, 02 Mar 2024 10:04:05 +0100 CET", time.Date(2024, 3, 2, 9, 4, 5, 0, time.UTC)},
+
+		// malformed variants
+		{"  Sat,  2 Mar 2024   10:04:05 +0000  ", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"Sun, 02 Mar 2024 10:04:05 +0000", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"Saturday, 02 March 2024 10:04:05 +0000", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"Sat., 02 Mar 2024 10:04:05 +0000", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"Mar 2, 2024 10:04:05 +0000", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"March 2, 2024", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{"02 Mar 2024 10:04:05", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"Sat, 02 Mar 2024 10:04:05 +0000 (UTC)", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"Sat Mar  2 10:04:05 2024", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+		{"2024/03/02 10:04:05", time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.value)
+		if err != nil {
+			t.Errorf("ParseDate(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("ParseDate(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	for _, value := range []string{"", "   ", "yesterday", "32 Mar 2024", "2024-13-01"} {
+		if got, err := ParseDate(value); err == nil {
+			t.Errorf("ParseDate(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestResolveDatesEstimates(t *testing.T) {
+	fetchedAt := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	feed := &Feed{Items: []Item{
+		{PubDate: "Sat, 02 Mar 2024 10:04:05 GMT"},
+		{PubDate: "sometime last week"},
+		{},
+	}}
+	resolveDates(feed, fetchedAt)
+
+	if feed.Items[0].DateEstimated || !feed.Items[0].Published.Equal(time.Date(2024, 3, 2, 10, 4, 5, 0, time.UTC)) {
+		t.Errorf("parsed item = %+v", feed.Items[0])
+	}
+	for _, item := range feed.Items[1:] {
+		if !item.DateEstimated || !item.Published.Equal(fetchedAt) {
+			t.Errorf("item %q = %v, estimated %v, want the fetch time", item.PubDate, item.Published, item.DateEstimated)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxPageSize caps how much of a web page is read while looking for feeds.
+const maxPageSize = 5 << 20
+
+// feedTypes are the <link type="..."> values that point at a feed.
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/rdf+xml":   true,
+	"application/json":      true,
+}
+
+// fallbackPaths are tried, relative to the site root, when a page doesn't
+// advertise any feeds.
+var fallbackPaths = []string{
+	"/feed",
+	"/rss.xml",
+	"/atom.xml",
+	"/feed.xml",
+	"/index.xml",
+	"/rss",
+	"/feed.json",
+}
+
+type DiscoveredFeed struct {
+	URL   string
+	Title string
+	Type  string
+}
+
+// DiscoverFeeds finds the feeds behind pageURL. If pageURL is itself a feed it
+// is the only result. Otherwise the page's <link rel="alternate"> tags are
+// used, and if there are none the common feed paths of the site are probed.
+func DiscoverFeeds(ctx context.Context, pageURL string) ([]DiscoveredFeed, error) {
+	body, contentType, base, canonical, err := fetchPage(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := ParseFeed(contentType, body)
+	if err == nil {
+		return []DiscoveredFeed{{
+			URL:   canonical.String(),
+			Title: feed.Title,
+			Type:  contentType,
+		}}, nil
+	}
+
+	found, err := FindFeedLinks(bytes.NewReader(body), base)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) > 0 {
+		return found, nil
+	}
+
+	for _, path := range fallbackPaths {
+		candidate := base.ResolveReference(&url.URL{Path: path})
+		body, contentType, _, canonical, err := fetchPage(ctx, candidate.String())
+		if err != nil {
+			continue
+		}
+		feed, err := ParseFeed(contentType, body)
+		if err != nil {
+			continue
+		}
+		return []DiscoveredFeed{{
+			URL:   canonical.String(),
+			Title: feed.Title,
+			Type:  contentType,
+		}}, nil
+	}
+
+	return nil, fmt.Errorf("no feeds found at %s", pageURL)
+}
+
+// FindFeedLinks returns the feeds an html page advertises with
+// <link rel="alternate" type="...">, with their urls resolved against base.
+func FindFeedLinks(r io.Reader, base *url.URL) ([]DiscoveredFeed, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []DiscoveredFeed
+	seen := make(map[string]bool)
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "base":
+				if href := attr(n, "href"); href != "" {
+					if ref, err := base.Parse(href); err == nil {
+						base = ref
+					}
+				}
+			case "link":
+				if isFeedLink(n) {
+					href, err := base.Parse(attr(n, "href"))
+					if err == nil && !seen[href.String()] {
+						seen[href.String()] = true
+						found = append(found, DiscoveredFeed{
+							URL:   href.String(),
+							Title: attr(n, "title"),
+							Type:  attr(n, "type"),
+						})
+					}
+				}
+			case "body":
+				// feed links belong in the head, don't walk the whole page
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	return found, nil
+}
+
+func isFeedLink(n *html.Node) bool {
+	if attr(n, "href") == "" {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(attr(n, "type"))
+	if !feedTypes[mediaType] {
+		return false
+	}
+	for _, rel := range strings.Fields(strings.ToLower(attr(n, "rel"))) {
+		if rel == "alternate" {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// fetchPage gets pageURL, returning the body, its content type, the url it
+// was finally served from after redirects, to resolve links against, and the
+// url to keep for it. That is pageURL, unless it moved permanently (301 or
+// 308), so temporary redirects and tracking hops aren't subscribed to.
+func fetchPage(ctx context.Context, pageURL string) ([]byte, string, *url.URL, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+	req.Header.Set("User-Agent", "gator")
+
+	canonical := req.URL
+	moved := true
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			status := req.Response.StatusCode
+			moved = moved && (status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect)
+			if moved {
+				canonical = req.URL
+			}
+			return nil
+		},
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", nil, nil, fmt.Errorf("fetching %s: unexpected status %s", pageURL, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+	return body, resp.Header.Get("Content-Type"), resp.Request.URL, canonical, nil
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindFeedLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want []DiscoveredFeed
+	}{
+		{
+			name: "relative hrefs",
+			page: `<html><head>
+<link rel="alternate" type="application/rss+xml" title="Posts" href="/feed.xml">
+<link rel="alternate" type="application/atom+xml" href="atom.xml">
+<link rel="stylesheet" type="text/css" href="/style.css">
+</head><body></body></html>`,
+			want: []DiscoveredFeed{
+				{URL: "https://example.com/feed.xml", Title: "Posts", Type: "application/rss+xml"},
+				{URL: "https://example.com/blog/atom.xml", Type: "application/atom+xml"},
+			},
+		},
+		{
+			name: "mixed case types and rels",
+			page: `<head>
+<link rel="Alternate Home" type="Application/RSS+XML; charset=utf-8" href="https://feeds.example.com/main">
+<LINK REL="ALTERNATE" TYPE="application/feed+json" HREF="/feed.json">
+</head>`,
+			want: []DiscoveredFeed{
+				{URL: "https://feeds.example.com/main", Type: "Application/RSS+XML; charset=utf-8"},
+				{URL: "https://example.com/feed.json", Type: "application/feed+json"},
+			},
+		},
+		{
+			name: "base href",
+			page: `<head>
+<base href="https://cdn.example.net/site/">
+<link rel="alternate" type="application/atom+xml" href="atom.xml">
+</head>`,
+			want: []DiscoveredFeed{
+				{URL: "https://cdn.example.net/site/atom.xml", Type: "application/atom+xml"},
+			},
+		},
+		{
+			name: "duplicates",
+			page: `<head>
+<link rel="alternate" type="application/rss+xml" href="/rss">
+<link rel="alternate" type="application/rss+xml" href="https://example.com/rss">
+</head>`,
+			want: []DiscoveredFeed{
+				{URL: "https://example.com/rss", Type: "application/rss+xml"},
+			},
+		},
+		{
+			name: "no feed links",
+			page: `<html><head><title>Hi</title><link rel="icon" href="/favicon.ico"></head>
+<body><a rel="alternate" type="application/rss+xml" href="/feed">feed</a></body></html>`,
+		},
+	}
+
+	base, _ := url.Parse("https://example.com/blog/")
+	for _, tt := range tests {
+		got, err := FindFeedLinks(strings.NewReader(tt.page), base)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: FindFeedLinks() =\n%+v\nwant\n%+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverFeedsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<rss version="2.0"><channel><title>Blog</title></channel></rss>`))
+	})
+	mux.Handle("/moved", http.RedirectHandler("/feed.xml", http.StatusMovedPermanently))
+	mux.Handle("/permanent", http.RedirectHandler("/feed.xml", http.StatusPermanentRedirect))
+	mux.Handle("/temporary", http.RedirectHandler("/feed.xml", http.StatusFound))
+	mux.Handle("/moved-to-temporary", http.RedirectHandler("/temporary", http.StatusMovedPermanently))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/feed.xml", "/feed.xml"},
+		{"/moved", "/feed.xml"},
+		{"/permanent", "/feed.xml"},
+		// temporary redirects and tracking hops keep the url given
+		{"/temporary", "/temporary"},
+		{"/moved-to-temporary", "/temporary"},
+	}
+	for _, tt := range tests {
+		found, err := DiscoverFeeds(context.Background(), server.URL+tt.path)
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if len(found) != 1 || found[0].URL != server.URL+tt.want {
+			t.Errorf("%s: DiscoverFeeds() = %+v, want %s", tt.path, found, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Syndication holds the sy: module elements (http://purl.org/rss/1.0/modules/syndication/)
+// that publishers use to say how often their feed changes.
+type Syndication struct {
+	UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+	UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+}
+
+var updatePeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// Interval is the time between updates implied by the sy: elements, or 0 if
+// they are absent or invalid.
+func (s Syndication) Interval() time.Duration {
+	period, ok := updatePeriods[strings.ToLower(strings.TrimSpace(s.UpdatePeriod))]
+	if !ok {
+		return 0
+	}
+	frequency, err := strconv.Atoi(strings.TrimSpace(s.UpdateFrequency))
+	if err != nil || frequency < 1 {
+		// the spec defaults the frequency to 1
+		frequency = 1
+	}
+	return period / time.Duration(frequency)
+}
+
+// updateHint combines an RSS <ttl> (in minutes) and the sy: elements. When both
+// are present the longer one wins, since either is a request not to poll sooner.
+func updateHint(ttl string, sy Syndication) time.Duration {
+	hint := sy.Interval()
+	minutes, err := strconv.Atoi(strings.TrimSpace(ttl))
+	if err == nil && minutes > 0 {
+		hint = max(hint, time.Duration(minutes)*time.Minute)
+	}
+	return hint
+}
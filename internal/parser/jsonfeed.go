@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Authors       []JSONFeedAuthor     `json:"authors"`
+	Author        *JSONFeedAuthor      `json:"author"` // JSON Feed 1.0, replaced by authors in 1.1
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+func parseJSONFeed(body []byte) (*Feed, error) {
+	var jf JSONFeed
+	err := json.Unmarshal(bytes.TrimPrefix(body, []byte("\ufeff")), &jf)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       jf.Title,
+		Link:        jf.HomePageURL,
+		Description: jf.Description,
+	}
+	for _, item := range jf.Items {
+		description := item.ContentHTML
+		if description == "" {
+			description = item.ContentText
+		}
+		if description == "" {
+			description = item.Summary
+		}
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+		authors := item.Authors
+		if len(authors) == 0 && item.Author != nil {
+			authors = []JSONFeedAuthor{*item.Author}
+		}
+		var names []string
+		for _, author := range authors {
+			if author.Name != "" {
+				names = append(names, author.Name)
+			}
+		}
+		var attachments []Attachment
+		for _, attachment := range item.Attachments {
+			attachments = append(attachments, Attachment{
+				URL:    attachment.URL,
+				Type:   attachment.MimeType,
+				Length: attachment.SizeInBytes,
+			})
+		}
+
+		feed.Items = append(feed.Items, Item{
+			ID:          item.ID,
+			Title:       item.Title,
+			Link:        item.URL,
+			Description: description,
+			PubDate:     pubDate,
+			Author:      strings.Join(names, ", "),
+			Attachments: attachments,
+		})
+	}
+	return feed, nil
+}
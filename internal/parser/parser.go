@@ -1,36 +1,80 @@
 package parser
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"html"
 	"io"
+	"mime"
 	"net/http"
+	"time"
 )
 
-type RSSFeed struct {
-	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
-	} `xml:"channel"`
+// Feed is the format independent representation of a fetched feed. Every
+// supported format is decoded into its own struct and then converted to this.
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []Item
+	// UpdateHint is how often the publisher says the feed changes, from <ttl>
+	// or the syndication module. 0 when the feed doesn't say.
+	UpdateHint time.Duration
+	// Cache holds the validators the server sent with this feed, to be passed
+	// back to FetchFeed on the next fetch.
+	Cache CacheHeaders
 }
 
-type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+// CacheHeaders are the ETag and Last-Modified validators of a response.
+type CacheHeaders struct {
+	ETag         string
+	LastModified string
 }
 
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// ErrNotModified is returned by FetchFeed when the server answers a conditional
+// request with 304, meaning the feed hasn't changed since the last fetch.
+var ErrNotModified = errors.New("feed not modified")
+
+type Item struct {
+	ID          string // guid in RSS, id in Atom and JSON Feed. May be empty.
+	Title       string
+	Link        string
+	Description string
+	PubDate     string
+	// Published is PubDate parsed by ParseDate. When PubDate is missing or
+	// can't be parsed it is the time of the fetch and DateEstimated is set.
+	Published     time.Time
+	DateEstimated bool
+	Author        string
+	Attachments   []Attachment
+}
+
+// Attachment is an RSS enclosure or JSON Feed attachment.
+type Attachment struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+// FetchFeed downloads and parses the feed at feedURL. If cache is not empty the
+// request is made conditional on it and ErrNotModified is returned on a 304.
+func FetchFeed(ctx context.Context, feedURL string, cache CacheHeaders) (*Feed, error) {
 	client := &http.Client{}
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, */*;q=0.8")
+	if cache.ETag != "" {
+		req.Header.Set("If-None-Match", cache.ETag)
+	}
+	if cache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.LastModified)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -39,29 +83,100 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", feedURL, resp.Status)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	var feed RSSFeed
+	feed, err := ParseFeed(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		return nil, err
+	}
+
+	feed.Cache = CacheHeaders{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
 
-	err = xml.Unmarshal(body, &feed)
+	unescapeFeed(feed)
+	resolveDates(feed, time.Now().UTC())
+
+	return feed, nil
+}
+
+// ParseFeed decodes body into a Feed. JSON Feeds are recognised by content type
+// or by the body starting with '{', xml feeds by their root element.
+func ParseFeed(contentType string, body []byte) (*Feed, error) {
+	if isJSON(contentType, body) {
+		return parseJSONFeed(body)
+	}
+
+	root, err := rootElement(body)
 	if err != nil {
 		return nil, err
 	}
 
-	unescapeFeed(&feed)
+	switch root.Local {
+	case "rss":
+		return parseRSS(body)
+	case "feed":
+		return parseAtom(body)
+	case "RDF":
+		return parseRDF(body)
+	default:
+		return nil, fmt.Errorf("unsupported feed format: <%s>", root.Local)
+	}
+}
+
+func isJSON(contentType string, body []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/feed+json" || mediaType == "application/json" {
+		return true
+	}
+	trimmed := bytes.TrimLeft(body, " \t\r\n\ufeff")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
 
-	return &feed, nil
+// rootElement returns the name of the first element in an xml document.
+func rootElement(body []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, fmt.Errorf("could not find root element: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+func resolveDates(feed *Feed, fetchedAt time.Time) {
+	for i, item := range feed.Items {
+		published, err := ParseDate(item.PubDate)
+		if err != nil {
+			feed.Items[i].Published = fetchedAt
+			feed.Items[i].DateEstimated = true
+			continue
+		}
+		feed.Items[i].Published = published
+	}
 }
 
-func unescapeFeed(feed *RSSFeed) {
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
-	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
+func unescapeFeed(feed *Feed) {
+	feed.Title = html.UnescapeString(feed.Title)
+	feed.Description = html.UnescapeString(feed.Description)
 
-	for i, item := range feed.Channel.Item {
-		feed.Channel.Item[i].Title = html.UnescapeString(item.Title)
-		feed.Channel.Item[i].Description = html.UnescapeString(item.Description)
+	for i, item := range feed.Items {
+		feed.Items[i].Title = html.UnescapeString(item.Title)
+		feed.Items[i].Description = html.UnescapeString(item.Description)
 	}
 }
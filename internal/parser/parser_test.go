@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFeedAtom(t *testing.T) {
+	body := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Blog</title>
+  <subtitle>Notes</subtitle>
+  <link rel="self" href="https://example.com/feed.atom"/>
+  <link href="https://example.com/"/>
+  <entry>
+    <id>urn:uuid:1</id>
+    <title type="html">Fish &amp;amp; Chips</title>
+    <link rel="alternate" href="https://example.com/fish"/>
+    <updated>2024-03-02T10:00:00Z</updated>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hi</p></div></content>
+    <author><name>Ann</name></author>
+    <author><name>Bob</name></author>
+  </entry>
+</feed>`)
+
+	feed, err := ParseFeed("application/atom+xml", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Example Blog" || feed.Link != "https://example.com/" || feed.Description != "Notes" {
+		t.Errorf("feed = %q %q %q", feed.Title, feed.Link, feed.Description)
+	}
+	if len(feed.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(feed.Items))
+	}
+	item := feed.Items[0]
+	if item.ID != "urn:uuid:1" || item.Link != "https://example.com/fish" || item.Author != "Ann, Bob" {
+		t.Errorf("item = %+v", item)
+	}
+	// published falls back to updated
+	if item.PubDate != "2024-03-02T10:00:00Z" {
+		t.Errorf("PubDate = %q", item.PubDate)
+	}
+	if item.Description != `<div xmlns="http://www.w3.org/1999/xhtml"><p>Hi</p></div>` {
+		t.Errorf("Description = %q", item.Description)
+	}
+}
+
+func TestParseFeedUnsupported(t *testing.T) {
+	_, err := ParseFeed("text/xml", []byte(`<html><body>not a feed</body></html>`))
+	if err == nil {
+		t.Error("expected an error for an html page")
+	}
+}
+
+func TestParseFeedJSON(t *testing.T) {
+	body := []byte("\ufeff" + `{
+  "version": "https://jsonfeed.org/version/1",
+  "title": "Example",
+  "home_page_url": "https://example.com/",
+  "items": [
+    {
+      "id": "1",
+      "url": "https://example.com/1",
+      "title": "First",
+      "content_text": "plain",
+      "date_modified": "2024-03-02T10:00:00Z",
+      "author": {"name": "Ann"},
+      "attachments": [{"url": "https://example.com/1.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 42}]
+    }
+  ]
+}`)
+
+	// sniffed from the body even when served as text/plain
+	feed, err := ParseFeed("text/plain", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Example" || feed.Link != "https://example.com/" {
+		t.Errorf("feed = %q %q", feed.Title, feed.Link)
+	}
+	if len(feed.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(feed.Items))
+	}
+	item := feed.Items[0]
+	if item.Description != "plain" || item.PubDate != "2024-03-02T10:00:00Z" || item.Author != "Ann" {
+		t.Errorf("item = %+v", item)
+	}
+	if len(item.Attachments) != 1 || item.Attachments[0].Type != "audio/mpeg" || item.Attachments[0].Length != 42 {
+		t.Errorf("Attachments = %+v", item.Attachments)
+	}
+}
+
+func TestParseFeedRDF(t *testing.T) {
+	body := []byte(`<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+  xmlns="http://purl.org/rss/1.0/"
+  xmlns:dc="http://purl.org/dc/elements/1.1/"
+  xmlns:sy="http://purl.org/rss/1.0/modules/syndication/">
+  <channel rdf:about="https://example.com/rss">
+    <title> Example </title>
+    <link>https://example.com/</link>
+    <sy:updatePeriod>daily</sy:updatePeriod>
+    <sy:updateFrequency>2</sy:updateFrequency>
+  </channel>
+  <item rdf:about="https://example.com/1">
+    <title>First</title>
+    <link>https://example.com/1</link>
+    <dc:date>2024-03-02T10:00:00+01:00</dc:date>
+    <dc:creator>Ann</dc:creator>
+  </item>
+</rdf:RDF>`)
+
+	feed, err := ParseFeed("application/rdf+xml", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Example" || feed.Link != "https://example.com/" {
+		t.Errorf("feed = %q %q", feed.Title, feed.Link)
+	}
+	if feed.UpdateHint != 12*time.Hour {
+		t.Errorf("UpdateHint = %v, want 12h", feed.UpdateHint)
+	}
+	if len(feed.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(feed.Items))
+	}
+	item := feed.Items[0]
+	if item.ID != "https://example.com/1" || item.PubDate != "2024-03-02T10:00:00+01:00" || item.Author != "Ann" {
+		t.Errorf("item = %+v", item)
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// RDFFeed is an RSS 1.0 document. Unlike RSS 2.0 the items are siblings of the
+// channel under the rdf:RDF root instead of being nested inside it.
+type RDFFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Syndication
+	} `xml:"channel"`
+	Item []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+func parseRDF(body []byte) (*Feed, error) {
+	var rdf RDFFeed
+	err := xml.Unmarshal(body, &rdf)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       strings.TrimSpace(rdf.Channel.Title),
+		Link:        strings.TrimSpace(rdf.Channel.Link),
+		Description: strings.TrimSpace(rdf.Channel.Description),
+		UpdateHint:  rdf.Channel.Syndication.Interval(),
+	}
+	for _, item := range rdf.Item {
+		feed.Items = append(feed.Items, Item{
+			ID:          item.About,
+			Title:       strings.TrimSpace(item.Title),
+			Link:        strings.TrimSpace(item.Link),
+			Description: strings.TrimSpace(item.Description),
+			PubDate:     strings.TrimSpace(item.Date),
+			Author:      strings.TrimSpace(item.Creator),
+		})
+	}
+	return feed, nil
+}
@@ -0,0 +1,70 @@
+package parser
+
+import "encoding/xml"
+
+type RSSFeed struct {
+	Channel struct {
+		Title       string    `xml:"title"`
+		Link        string    `xml:"link"`
+		Description string    `xml:"description"`
+		TTL         string    `xml:"ttl"`
+		Item        []RSSItem `xml:"item"`
+		Syndication
+	} `xml:"channel"`
+}
+
+type RSSItem struct {
+	GUID        string         `xml:"guid"`
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	Description string         `xml:"description"`
+	PubDate     string         `xml:"pubDate"`
+	DCDate      string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author      string         `xml:"author"`
+	Enclosure   []RSSEnclosure `xml:"enclosure"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+func parseRSS(body []byte) (*Feed, error) {
+	var rss RSSFeed
+	err := xml.Unmarshal(body, &rss)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       rss.Channel.Title,
+		Link:        rss.Channel.Link,
+		Description: rss.Channel.Description,
+		UpdateHint:  updateHint(rss.Channel.TTL, rss.Channel.Syndication),
+	}
+	for _, item := range rss.Channel.Item {
+		var attachments []Attachment
+		for _, enclosure := range item.Enclosure {
+			attachments = append(attachments, Attachment{
+				URL:    enclosure.URL,
+				Type:   enclosure.Type,
+				Length: enclosure.Length,
+			})
+		}
+		pubDate := item.PubDate
+		if pubDate == "" {
+			pubDate = item.DCDate
+		}
+		feed.Items = append(feed.Items, Item{
+			ID:          item.GUID,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PubDate:     pubDate,
+			Author:      item.Author,
+			Attachments: attachments,
+		})
+	}
+	return feed, nil
+}
@@ -0,0 +1,465 @@
+// Package render turns the html of posts into text for terminals, either plain
+// or styled with ANSI escape codes.
+package render
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const defaultWidth = 80
+
+const (
+	reset     = "\033[0m"
+	bold      = "\033[1m"
+	faint     = "\033[2m"
+	italic    = "\033[3m"
+	underline = "\033[4m"
+	strike    = "\033[9m"
+	cyan      = "\033[36m"
+)
+
+// Options controls how HTML renders.
+type Options struct {
+	// Width to wrap lines at, 0 to not wrap
+	Width int
+	// Style uses ANSI escape codes for headings, emphasis, links and code
+	Style bool
+	// BaseURL resolves relative links, usually the post's url
+	BaseURL string
+}
+
+// OptionsFor picks the width and styling for output to f: the terminal's
+// width and styles, or 80 columns of plain text when f isn't a terminal.
+// NO_COLOR turns styles off.
+func OptionsFor(f *os.File) Options {
+	opts := Options{Width: defaultWidth}
+	if !term.IsTerminal(f.Fd()) {
+		return opts
+	}
+	opts.Style = os.Getenv("NO_COLOR") == ""
+
+	width, _, err := term.GetSize(f.Fd())
+	if err == nil && width > 0 {
+		opts.Width = width
+	} else if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		opts.Width = columns
+	}
+	return opts
+}
+
+// HTML renders body as wrapped text. Headings, lists, quotes and code blocks
+// keep their shape, images become their alt text and links are numbered and
+// listed at the end as footnotes.
+func HTML(body string, opts Options) string {
+	nodes, err := html.ParseFragment(strings.NewReader(body), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		// not even html, so show it as it is
+		return body
+	}
+
+	r := &renderer{opts: opts, space: true}
+	r.base, _ = url.Parse(opts.BaseURL)
+	for _, n := range nodes {
+		r.walk(n, "")
+	}
+	r.flush("", true)
+	// blocks like pre leave a gap after them, even at the end
+	for len(r.lines) > 0 && r.lines[len(r.lines)-1] == "" {
+		r.lines = r.lines[:len(r.lines)-1]
+	}
+
+	if len(r.links) > 0 {
+		r.gap("")
+		for i, link := range r.links {
+			r.lines = append(r.lines, r.style(faint, fmt.Sprintf("[%d] %s", i+1, link)))
+		}
+	}
+	return strings.Join(r.lines, "\n")
+}
+
+type renderer struct {
+	opts Options
+	base *url.URL
+
+	lines  []string
+	inline strings.Builder
+	// space is whether inline text ends in whitespace, which collapses
+	space bool
+	// styles are the escape codes in effect, reapplied after each reset
+	styles []string
+	// marker is the prefix of a list item's first line, bullet or number
+	// included, until that line is written. markerIndent is the prefix of the
+	// item's other lines, which the first line's prefix stands in for.
+	marker       string
+	markerIndent string
+	links        []string
+}
+
+func (r *renderer) walk(n *html.Node, prefix string) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		r.children(n, prefix)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Noscript, atom.Template, atom.Iframe, atom.Svg, atom.Object:
+		return
+
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main, atom.Aside, atom.Nav,
+		atom.Figure, atom.Figcaption, atom.Dl, atom.Dt, atom.Address, atom.Details, atom.Summary, atom.Table, atom.Center:
+		r.flush(prefix, true)
+		r.children(n, prefix)
+		r.flush(prefix, true)
+
+	case atom.Dd:
+		r.flush(prefix, false)
+		r.children(n, prefix+"    ")
+		r.flush(prefix+"    ", false)
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		r.flush(prefix, true)
+		level := int(n.Data[1] - '0')
+		if r.opts.Style {
+			code := bold
+			if level <= 2 {
+				code += underline
+			}
+			r.push(code)
+			r.children(n, prefix)
+			r.pop()
+		} else {
+			r.write(strings.Repeat("#", level) + " ")
+			r.children(n, prefix)
+		}
+		r.flush(prefix, true)
+
+	case atom.Ul, atom.Ol:
+		r.flush(prefix, true)
+		// nested lists stay tight against their item
+		if !inItem(n) {
+			r.gap(prefix)
+		}
+		number := 1
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			number = start
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+				r.walk(c, prefix)
+				continue
+			}
+			marker := "• "
+			if n.DataAtom == atom.Ol {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			r.item(c, prefix, marker)
+		}
+		r.flush(prefix, true)
+
+	case atom.Li:
+		// an item outside of a list
+		r.item(n, prefix, "• ")
+
+	case atom.Blockquote:
+		r.flush(prefix, true)
+		r.gap(prefix)
+		quote := prefix + r.style(faint, "│") + " "
+		r.children(n, quote)
+		r.flush(quote, true)
+
+	case atom.Pre:
+		r.flush(prefix, true)
+		r.pre(n, prefix)
+
+	case atom.Hr:
+		r.flush(prefix, true)
+		width := r.opts.Width - ansi.StringWidth(prefix)
+		if width < 1 {
+			width = defaultWidth
+		}
+		r.lines = append(r.lines, r.firstPrefix(prefix)+r.style(faint, strings.Repeat("─", width)))
+
+	case atom.Br:
+		r.write("\n")
+		r.space = true
+
+	case atom.Tr:
+		r.flush(prefix, false)
+		cells := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+				if cells > 0 {
+					r.write(" | ")
+				}
+				cells++
+			}
+			r.walk(c, prefix)
+		}
+		r.flush(prefix, false)
+
+	case atom.Th:
+		r.inlineStyle(n, prefix, bold, "", "")
+
+	case atom.Img:
+		alt := strings.TrimSpace(attr(n, "alt"))
+		text := "[image]"
+		if alt != "" {
+			text = "[image: " + alt + "]"
+		}
+		r.space = false
+		r.write(r.style(faint, text))
+
+	case atom.A:
+		r.link(n, prefix)
+
+	case atom.Strong, atom.B:
+		r.inlineStyle(n, prefix, bold, "", "")
+	case atom.Em, atom.I, atom.Cite:
+		r.inlineStyle(n, prefix, italic, "", "")
+	case atom.Del, atom.S, atom.Strike:
+		r.inlineStyle(n, prefix, strike, "", "")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		r.inlineStyle(n, prefix, cyan, "`", "`")
+
+	default:
+		r.children(n, prefix)
+	}
+}
+
+func (r *renderer) children(n *html.Node, prefix string) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c, prefix)
+	}
+}
+
+// item renders a list item, the marker on its first line and the rest
+// indented to line up with the text after it.
+func (r *renderer) item(n *html.Node, prefix, marker string) {
+	r.flush(prefix, false)
+	// an item that starts with a nested list keeps its own marker too
+	r.marker = r.firstPrefix(prefix) + marker
+	r.markerIndent = prefix + strings.Repeat(" ", ansi.StringWidth(marker))
+	indent := r.markerIndent
+	r.children(n, indent)
+	r.flush(indent, false)
+	r.marker = ""
+}
+
+// firstPrefix is the prefix of the next line at prefix: prefix itself, or the
+// pending list marker in place of the indentation made for it.
+func (r *renderer) firstPrefix(prefix string) string {
+	if r.marker == "" || !strings.HasPrefix(prefix, r.markerIndent) {
+		return prefix
+	}
+	first := r.marker + prefix[len(r.markerIndent):]
+	r.marker = ""
+	return first
+}
+
+// text adds inline text, collapsing whitespace the way browsers do.
+func (r *renderer) text(data string) {
+	for _, c := range data {
+		if unicode.IsSpace(c) {
+			if !r.space {
+				r.inline.WriteByte(' ')
+				r.space = true
+			}
+			continue
+		}
+		r.inline.WriteRune(c)
+		r.space = false
+	}
+}
+
+func (r *renderer) write(s string) {
+	r.inline.WriteString(s)
+}
+
+func (r *renderer) inlineStyle(n *html.Node, prefix, code, open, close string) {
+	if r.opts.Style {
+		r.push(code)
+		r.children(n, prefix)
+		r.pop()
+		return
+	}
+	r.write(open)
+	r.children(n, prefix)
+	r.write(close)
+}
+
+func (r *renderer) push(code string) {
+	r.styles = append(r.styles, code)
+	r.write(code)
+}
+
+func (r *renderer) pop() {
+	r.styles = r.styles[:len(r.styles)-1]
+	r.write(reset + strings.Join(r.styles, ""))
+}
+
+// style wraps s in code, if styles are on.
+func (r *renderer) style(code, s string) string {
+	if !r.opts.Style {
+		return s
+	}
+	return code + s + reset
+}
+
+// link renders a link's text followed by its footnote number, unless the text
+// already is the url.
+func (r *renderer) link(n *html.Node, prefix string) {
+	href := strings.TrimSpace(attr(n, "href"))
+	// anchors, links within the post and scripts have nothing to show, and
+	// must be told apart before they are made absolute
+	skip := href == "" || strings.HasPrefix(href, "#") ||
+		strings.HasPrefix(strings.ToLower(href), "javascript:")
+	if r.base != nil && !skip {
+		if u, err := r.base.Parse(href); err == nil {
+			href = u.String()
+		}
+	}
+
+	start := r.inline.Len()
+	if r.opts.Style {
+		r.push(underline)
+		r.children(n, prefix)
+		r.pop()
+	} else {
+		r.children(n, prefix)
+	}
+
+	text := strings.TrimSpace(ansi.Strip(r.inline.String()[min(start, r.inline.Len()):]))
+	if skip || text == href || text == strings.TrimPrefix(strings.TrimPrefix(href, "https://"), "http://") {
+		return
+	}
+
+	number := 0
+	for i, link := range r.links {
+		if link == href {
+			number = i + 1
+		}
+	}
+	if number == 0 {
+		r.links = append(r.links, href)
+		number = len(r.links)
+	}
+	r.write(r.style(faint, fmt.Sprintf("[%d]", number)))
+	r.space = false
+}
+
+// pre renders a code block as is, indented and unwrapped.
+func (r *renderer) pre(n *html.Node, prefix string) {
+	var code strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			code.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+			code.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+
+	text := strings.Trim(strings.ReplaceAll(code.String(), "\t", "    "), "\n")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	r.gap(prefix)
+	first := r.firstPrefix(prefix)
+	for i, line := range strings.Split(text, "\n") {
+		linePrefix := prefix
+		if i == 0 {
+			linePrefix = first
+		}
+		r.lines = append(r.lines, strings.TrimRight(linePrefix+"    "+r.style(cyan, line), " "))
+	}
+	r.gap(prefix)
+}
+
+// flush wraps the inline text gathered so far into lines, after a blank line
+// if gap is set.
+func (r *renderer) flush(prefix string, gap bool) {
+	text := strings.TrimSpace(r.inline.String())
+	r.inline.Reset()
+	r.space = true
+	if len(r.styles) > 0 {
+		// close the styles for this block and open them again for the next
+		text += reset
+		r.write(strings.Join(r.styles, ""))
+	}
+	if strings.TrimSpace(ansi.Strip(text)) == "" {
+		return
+	}
+
+	if gap {
+		r.gap(prefix)
+	}
+	first := r.firstPrefix(prefix)
+
+	width := 0
+	if r.opts.Width > 0 {
+		width = max(r.opts.Width-ansi.StringWidth(prefix), 10)
+	}
+	for i, line := range strings.Split(ansi.Wrap(text, width, ""), "\n") {
+		line = strings.TrimSpace(line)
+		if i == 0 {
+			r.lines = append(r.lines, first+line)
+		} else {
+			r.lines = append(r.lines, prefix+line)
+		}
+	}
+}
+
+// gap adds a blank line, unless there already is one, nothing came before or
+// the first line of a list item is still to come.
+func (r *renderer) gap(prefix string) {
+	if len(r.lines) == 0 || r.marker != "" {
+		return
+	}
+	blank := strings.TrimRight(prefix, " ")
+	if last := r.lines[len(r.lines)-1]; last != "" && last != blank {
+		r.lines = append(r.lines, blank)
+	}
+}
+
+func inItem(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.DataAtom == atom.Li {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "paragraphs",
+			body: "<p>one  two\nthree</p><p>four</p>",
+			want: "one two three\n\nfour",
+		},
+		{
+			name: "nested list",
+			body: "<ul><li>outer<ul><li>inner</li><li>two</li></ul></li><li>next</li></ul>",
+			want: "• outer\n  • inner\n  • two\n• next",
+		},
+		{
+			name: "list in a list item",
+			body: "<ul><li><ul><li>inner</li></ul></li></ul>",
+			want: "• • inner",
+		},
+		{
+			name: "ordered list",
+			body: "<ol start=\"3\"><li>three</li><li>four</li></ol>",
+			want: "3. three\n4. four",
+		},
+		{
+			name: "quote",
+			body: "<p>before</p><blockquote><p>one</p><p>two</p></blockquote>",
+			want: "before\n\n│ one\n│\n│ two",
+		},
+		{
+			name: "quote in a list item",
+			body: "<ul><li><blockquote>quoted text</blockquote></li></ul>",
+			want: "• │ quoted text",
+		},
+		{
+			name: "pre",
+			body: "<p>code:</p><pre>func main() {\n\tprintln()\n}</pre>",
+			want: "code:\n\n    func main() {\n        println()\n    }",
+		},
+		{
+			name: "pre in a list item",
+			body: "<ul><li><pre>code</pre></li></ul>",
+			want: "•     code",
+		},
+		{
+			name: "links",
+			body: `<p><a href="/a">a</a>, <a href="https://b.example">b.example</a></p>`,
+			want: "a[1], b.example\n\n[1] https://example.com/a",
+		},
+		{
+			name: "anchors",
+			body: `<p><a name="x">x</a> one<a href="#fn1">1</a> <a href=" JavaScript:void(0)">js</a></p>`,
+			want: "x one1 js",
+		},
+		{
+			name: "wrapping",
+			body: "<ul><li>aaaa bbbb cccc dddd eeee ffff</li></ul>",
+			want: "• aaaa bbbb cccc dddd\n  eeee ffff",
+		},
+	}
+	for _, tt := range tests {
+		got := HTML(tt.body, Options{Width: 22, BaseURL: "https://example.com/post"})
+		if got != tt.want {
+			t.Errorf("%s: HTML(%q) =\n%s\nwant\n%s", tt.name, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLStyledMarker(t *testing.T) {
+	got := HTML("<ul><li><blockquote>quoted text</blockquote></li></ul>", Options{Style: true})
+	want := "• " + faint + "│" + reset + " quoted text"
+	if !utf8.ValidString(got) || got != want {
+		t.Errorf("HTML() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,346 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/quanchobi/gator/internal/cursor"
+	"github.com/quanchobi/gator/internal/database"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name"`
+}
+
+type Feed struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	AddedBy       string     `json:"added_by,omitempty"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	Disabled      bool       `json:"disabled"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+type Follow struct {
+	ID       uuid.UUID `json:"id"`
+	FeedID   uuid.UUID `json:"feed_id"`
+	FeedName string    `json:"feed_name"`
+	URL      string    `json:"url"`
+	Folder   string    `json:"folder,omitempty"`
+}
+
+type Post struct {
+	ID                   uuid.UUID `json:"id"`
+	FeedID               uuid.UUID `json:"feed_id"`
+	FeedName             string    `json:"feed_name"`
+	FeedURL              string    `json:"feed_url"`
+	Title                string    `json:"title"`
+	URL                  string    `json:"url"`
+	Description          string    `json:"description"`
+	PublishedAt          time.Time `json:"published_at"`
+	PublishedAtEstimated bool      `json:"published_at_estimated"`
+	Read                 bool      `json:"read"`
+	Starred              bool      `json:"starred"`
+}
+
+type PostPage struct {
+	Posts      []Post  `json:"posts"`
+	NextCursor *string `json:"next_cursor"`
+	NextOffset *int    `json:"next_offset"`
+}
+
+func userFromDB(user database.User) User {
+	return User{
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		Name:      user.Name,
+	}
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func (srv *Server) handleMe(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondJSON(w, http.StatusOK, userFromDB(user))
+}
+
+func (srv *Server) handleListUsers(w http.ResponseWriter, r *http.Request, user database.User) {
+	users, err := srv.Db.GetUsers(r.Context())
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+	resp := make([]User, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, userFromDB(u))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (srv *Server) handleListFeeds(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := srv.Db.GetFeeds(r.Context())
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+	resp := make([]Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		resp = append(resp, Feed{
+			ID:            feed.ID,
+			CreatedAt:     feed.CreatedAt,
+			Name:          feed.Name,
+			URL:           feed.Url,
+			AddedBy:       feed.Username,
+			LastFetchedAt: nullTime(feed.LastFetchedAt),
+			Disabled:      feed.Disabled,
+			LastError:     feed.LastError,
+		})
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// handleCreateFeed adds a feed and follows it, like the addfeed command.
+func (srv *Server) handleCreateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	var req struct {
+		Name   string `json:"name"`
+		URL    string `json:"url"`
+		Folder string `json:"folder"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.URL == "" {
+		respondError(w, http.StatusBadRequest, "name and url are required")
+		return
+	}
+
+	feed, err := srv.Db.CreateFeed(r.Context(),
+		database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      req.Name,
+			Url:       req.URL,
+			UserID:    user.ID,
+		},
+	)
+	if isUniqueViolation(err) {
+		respondError(w, http.StatusConflict, "a feed with that url already exists, follow it instead")
+		return
+	}
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+
+	_, err = srv.Db.CreateFeedFollow(r.Context(),
+		database.CreateFeedFollowParams{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			FeedID: feed.ID,
+			Folder: req.Folder,
+		},
+	)
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, Feed{
+		ID:        feed.ID,
+		CreatedAt: feed.CreatedAt,
+		Name:      feed.Name,
+		URL:       feed.Url,
+		AddedBy:   user.Name,
+	})
+}
+
+func (srv *Server) handleListFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := srv.Db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+	resp := make([]Follow, 0, len(follows))
+	for _, follow := range follows {
+		resp = append(resp, Follow{
+			ID:       follow.ID,
+			FeedID:   follow.FeedID,
+			FeedName: follow.Feedname,
+			URL:      follow.Url,
+			Folder:   follow.Folder,
+		})
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (srv *Server) handleFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	var req struct {
+		URL    string `json:"url"`
+		Folder string `json:"folder"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	feed, err := srv.Db.GetFeedByURL(r.Context(), req.URL)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(w, http.StatusNotFound, "no feed with that url, create it first")
+		return
+	}
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+
+	follow, err := srv.Db.CreateFeedFollow(r.Context(),
+		database.CreateFeedFollowParams{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			FeedID: feed.ID,
+			Folder: req.Folder,
+		},
+	)
+	if isUniqueViolation(err) {
+		respondError(w, http.StatusConflict, "already following that feed")
+		return
+	}
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, Follow{
+		ID:       follow.ID,
+		FeedID:   follow.FeedID,
+		FeedName: follow.Feedname,
+		URL:      feed.Url,
+		Folder:   follow.Folder,
+	})
+}
+
+func (srv *Server) handleUnfollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := uuid.Parse(r.PathValue("feedID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid feed id")
+		return
+	}
+
+	deleted, err := srv.Db.DeleteFeedFollow(r.Context(),
+		database.DeleteFeedFollowParams{
+			UserID: user.ID,
+			FeedID: feedID,
+		},
+	)
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+	if deleted == 0 {
+		respondError(w, http.StatusNotFound, "not following that feed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListPosts is browse over http. Takes limit, all (to include read
+// posts) and either after, the next_cursor of the previous page, or offset.
+func (srv *Server) handleListPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	limit, err := intParam(query.Get("limit"), defaultPageSize)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		respondError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+		return
+	}
+	offset, err := intParam(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		respondError(w, http.StatusBadRequest, "offset must be a positive number")
+		return
+	}
+	all, _ := strconv.ParseBool(query.Get("all"))
+
+	// fetch one extra post to know if there is a next page
+	params := database.GetPostsForUserParams{
+		UserID:      user.ID,
+		IncludeRead: all,
+		Limit:       int32(limit + 1),
+		Offset:      int32(offset),
+	}
+	if after := query.Get("after"); after != "" {
+		if offset != 0 {
+			respondError(w, http.StatusBadRequest, "after and offset can't be used together")
+			return
+		}
+		publishedAt, id, err := cursor.Decode(after)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		params.AfterPublishedAt = sql.NullTime{Time: publishedAt, Valid: true}
+		params.AfterID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	posts, err := srv.Db.GetPostsForUser(r.Context(), params)
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+
+	page := PostPage{Posts: make([]Post, 0, limit)}
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1]
+		nextCursor := cursor.Encode(last.PublishedAt, last.ID)
+		page.NextCursor = &nextCursor
+		if !params.AfterID.Valid {
+			next := offset + limit
+			page.NextOffset = &next
+		}
+	}
+	for _, post := range posts {
+		page.Posts = append(page.Posts, Post{
+			ID:                   post.ID,
+			FeedID:               post.FeedID,
+			FeedName:             post.FeedName,
+			FeedURL:              post.FeedUrl,
+			Title:                post.Title,
+			URL:                  post.Url,
+			Description:          post.Description,
+			PublishedAt:          post.PublishedAt,
+			PublishedAtEstimated: post.PublishedAtEstimated,
+			Read:                 post.Read,
+			Starred:              post.Starred,
+		})
+	}
+	respondJSON(w, http.StatusOK, page)
+}
+
+func intParam(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
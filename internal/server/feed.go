@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/quanchobi/gator/internal/database"
+	"github.com/quanchobi/gator/internal/feedgen"
+)
+
+const defaultFeedSize = 50
+
+// handleFeed serves the user's posts, read or not, as RSS or Atom. Takes a
+// limit query parameter.
+func (srv *Server) handleFeed(format string) func(w http.ResponseWriter, r *http.Request, user database.User) {
+	return func(w http.ResponseWriter, r *http.Request, user database.User) {
+		limit, err := intParam(r.URL.Query().Get("limit"), defaultFeedSize)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			respondError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+
+		posts, err := srv.Db.GetPostsForUser(r.Context(),
+			database.GetPostsForUserParams{
+				UserID:      user.ID,
+				IncludeRead: true,
+				Limit:       int32(limit),
+			},
+		)
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		feed := feedgen.UserFeed(user, posts, fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.Path))
+
+		if format == "atom" {
+			w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+			err = feedgen.WriteAtom(w, feed)
+		} else {
+			w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+			err = feedgen.WriteRSS(w, feed)
+		}
+		if err != nil {
+			respondInternalError(w, err)
+		}
+	}
+}
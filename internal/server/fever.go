@@ -0,0 +1,375 @@
+package server
+
+import (
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+)
+
+// Fever API (https://feedafever.com/api), spoken by many mobile readers. Fever
+// identifies everything by integer, so feeds and posts are exposed by their
+// seq and folders by a hash of their name.
+
+const (
+	feverAPIVersion = 3
+	feverPageSize   = 50
+	// feverAllGroup is the group id Fever clients use for "all feeds"
+	feverAllGroup = 0
+)
+
+type feverGroup struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+type feverFeedsGroup struct {
+	GroupID int64  `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+type feverFeed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	SiteURL           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+type feverItem struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	URL           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+// FeverKey is the api_key a Fever client sends for username and password.
+func FeverKey(username, password string) string {
+	sum := md5.Sum([]byte(username + ":" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+func feverGroupID(folder string) int64 {
+	// never 0, that is the "all feeds" group
+	return int64(crc32.ChecksumIEEE([]byte(folder))&0x7fffffff) + 1
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func joinSeqs(seqs []int64) string {
+	parts := make([]string, 0, len(seqs))
+	for _, seq := range seqs {
+		parts = append(parts, strconv.FormatInt(seq, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (srv *Server) handleFever(w http.ResponseWriter, r *http.Request) {
+	resp := map[string]any{
+		"api_version": feverAPIVersion,
+		"auth":        0,
+	}
+
+	user, err := srv.Db.GetUserByAPIToken(r.Context(),
+		database.GetUserByAPITokenParams{
+			TokenHash: HashToken(strings.ToLower(r.FormValue("api_key"))),
+			Scope:     ScopeFever,
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondJSON(w, http.StatusOK, resp)
+		return
+	}
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+	resp["auth"] = 1
+
+	if r.FormValue("mark") != "" {
+		err = srv.feverMark(r, user)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	feeds, err := srv.Db.GetFollowedFeeds(r.Context(), user.ID)
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+	var lastRefreshed int64
+	for _, feed := range feeds {
+		if feed.LastFetchedAt.Valid {
+			lastRefreshed = max(lastRefreshed, feed.LastFetchedAt.Time.Unix())
+		}
+	}
+	resp["last_refreshed_on_time"] = lastRefreshed
+
+	query := r.URL.Query()
+	if query.Has("groups") || query.Has("feeds") {
+		groups, feedsGroups := feverGroups(feeds)
+		if query.Has("groups") {
+			resp["groups"] = groups
+		}
+		resp["feeds_groups"] = feedsGroups
+	}
+
+	if query.Has("feeds") {
+		list := make([]feverFeed, 0, len(feeds))
+		for _, feed := range feeds {
+			f := feverFeed{
+				ID:      feed.Seq,
+				Title:   feed.Name,
+				URL:     feed.Url,
+				SiteURL: feed.SiteUrl,
+			}
+			if feed.LastFetchedAt.Valid {
+				f.LastUpdatedOnTime = feed.LastFetchedAt.Time.Unix()
+			}
+			list = append(list, f)
+		}
+		resp["feeds"] = list
+	}
+
+	if query.Has("favicons") {
+		resp["favicons"] = []any{}
+	}
+	if query.Has("links") {
+		resp["links"] = []any{}
+	}
+
+	if query.Has("items") {
+		items, total, err := srv.feverItems(r, user)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		resp["items"] = items
+		resp["total_items"] = total
+	}
+
+	if query.Has("unread_item_ids") || r.FormValue("mark") != "" {
+		seqs, err := srv.Db.GetUnreadPostSeqs(r.Context(), user.ID)
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+		resp["unread_item_ids"] = joinSeqs(seqs)
+	}
+
+	if query.Has("saved_item_ids") || r.FormValue("mark") != "" {
+		seqs, err := srv.Db.GetStarredPostSeqs(r.Context(), user.ID)
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+		resp["saved_item_ids"] = joinSeqs(seqs)
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// feverGroups turns the folders of the user's follows into Fever groups.
+func feverGroups(feeds []database.GetFollowedFeedsRow) ([]feverGroup, []feverFeedsGroup) {
+	members := make(map[string][]int64)
+	for _, feed := range feeds {
+		if feed.Folder != "" {
+			members[feed.Folder] = append(members[feed.Folder], feed.Seq)
+		}
+	}
+
+	folders := make([]string, 0, len(members))
+	for folder := range members {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+
+	groups := make([]feverGroup, 0, len(folders))
+	feedsGroups := make([]feverFeedsGroup, 0, len(folders))
+	for _, folder := range folders {
+		id := feverGroupID(folder)
+		groups = append(groups, feverGroup{ID: id, Title: folder})
+		feedsGroups = append(feedsGroups, feverFeedsGroup{GroupID: id, FeedIDs: joinSeqs(members[folder])})
+	}
+	return groups, feedsGroups
+}
+
+func (srv *Server) feverItems(r *http.Request, user database.User) ([]feverItem, int64, error) {
+	query := r.URL.Query()
+	params := database.GetFeverItemsParams{
+		UserID: user.ID,
+		Seqs:   []int64{},
+		Limit:  feverPageSize,
+	}
+
+	var err error
+	if v := query.Get("since_id"); v != "" {
+		params.SinceSeq, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	if v := query.Get("max_id"); v != "" {
+		params.MaxSeq, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	if v := query.Get("with_ids"); v != "" {
+		for _, id := range strings.Split(v, ",") {
+			seq, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+			if err != nil {
+				return nil, 0, err
+			}
+			params.Seqs = append(params.Seqs, seq)
+		}
+	}
+
+	rows, err := srv.Db.GetFeverItems(r.Context(), params)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := srv.Db.CountPostsForUser(r.Context(), user.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]feverItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, feverItem{
+			ID:            row.Seq,
+			FeedID:        row.FeedSeq,
+			Title:         row.Title,
+			HTML:          row.Description,
+			URL:           row.Url,
+			IsSaved:       boolInt(row.Starred),
+			IsRead:        boolInt(row.Read),
+			CreatedOnTime: row.PublishedAt.Unix(),
+		})
+	}
+	return items, total, nil
+}
+
+// feverMark handles mark=item|feed|group, as=read|unread|saved|unsaved, id=
+// and, for feeds and groups, before=<unix time>.
+func (srv *Server) feverMark(r *http.Request, user database.User) error {
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		return errors.New("invalid id")
+	}
+	as := r.FormValue("as")
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+
+	switch r.FormValue("mark") {
+	case "item":
+		postID, err := srv.Db.GetFollowedPostID(r.Context(),
+			database.GetFollowedPostIDParams{
+				Seq:    id,
+				UserID: user.ID,
+			},
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("no such item")
+		}
+		if err != nil {
+			return err
+		}
+		return srv.setPostState(r, user, postID, as)
+	case "feed", "group":
+		if as != "read" {
+			return errors.New("feeds and groups can only be marked read")
+		}
+		before := time.Now()
+		if v := r.FormValue("before"); v != "" {
+			unix, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return errors.New("invalid before")
+			}
+			before = time.Unix(unix, 0).UTC()
+		}
+
+		feeds, err := srv.Db.GetFollowedFeeds(r.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+		for _, feed := range feeds {
+			inScope := feed.Seq == id
+			if r.FormValue("mark") == "group" {
+				inScope = id == feverAllGroup || (feed.Folder != "" && feverGroupID(feed.Folder) == id)
+			}
+			if !inScope {
+				continue
+			}
+			_, err = srv.Db.SetFeedPostsReadBefore(r.Context(),
+				database.SetFeedPostsReadBeforeParams{
+					UserID:      user.ID,
+					ReadAt:      now,
+					FeedID:      feed.ID,
+					PublishedAt: before,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.New("mark must be item, feed or group")
+	}
+}
+
+// setPostState applies read, unread, saved or unsaved to a post.
+func (srv *Server) setPostState(r *http.Request, user database.User, postID uuid.UUID, as string) error {
+	switch as {
+	case "read", "unread":
+		return srv.Db.SetPostRead(r.Context(),
+			database.SetPostReadParams{
+				UserID: user.ID,
+				PostID: postID,
+				Read:   as == "read",
+				ReadAt: sql.NullTime{Time: time.Now(), Valid: as == "read"},
+			},
+		)
+	case "saved":
+		return srv.Db.StarPost(r.Context(),
+			database.StarPostParams{
+				UserID:    user.ID,
+				PostID:    postID,
+				CreatedAt: time.Now(),
+			},
+		)
+	case "unsaved":
+		_, err := srv.Db.UnstarPost(r.Context(),
+			database.UnstarPostParams{
+				UserID: user.ID,
+				PostID: postID,
+			},
+		)
+		return err
+	default:
+		return errors.New("as must be read, unread, saved or unsaved")
+	}
+}
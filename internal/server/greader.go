@@ -0,0 +1,762 @@
+package server
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+)
+
+// Google Reader API, as spoken by FreshRSS and Miniflux and the clients that
+// sync with them. Like Fever it wants integer ids, so feeds are feed/<seq>,
+// items are their post's seq and folders are labels.
+
+const (
+	greaderDefaultCount = 20
+	greaderMaxContents  = 1000
+	greaderMaxIDs       = 10000
+
+	greaderReadingList = "user/-/state/com.google/reading-list"
+	greaderRead        = "user/-/state/com.google/read"
+	greaderStarred     = "user/-/state/com.google/starred"
+	greaderLabelPrefix = "user/-/label/"
+	greaderFeedPrefix  = "feed/"
+	greaderItemPrefix  = "tag:google.com,2005:reader/item/"
+)
+
+type greaderCategory struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type greaderSubscription struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Categories []greaderCategory `json:"categories"`
+	URL        string            `json:"url"`
+	HTMLURL    string            `json:"htmlUrl"`
+	IconURL    string            `json:"iconUrl"`
+}
+
+type greaderTag struct {
+	ID   string `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+type greaderLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type greaderContent struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type greaderOrigin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type greaderItem struct {
+	ID            string         `json:"id"`
+	CrawlTimeMsec string         `json:"crawlTimeMsec"`
+	TimestampUsec string         `json:"timestampUsec"`
+	Published     int64          `json:"published"`
+	Updated       int64          `json:"updated"`
+	Title         string         `json:"title"`
+	Canonical     []greaderLink  `json:"canonical"`
+	Alternate     []greaderLink  `json:"alternate"`
+	Summary       greaderContent `json:"summary"`
+	Categories    []string       `json:"categories"`
+	Origin        greaderOrigin  `json:"origin"`
+	Author        string         `json:"author"`
+}
+
+type greaderItemRef struct {
+	ID              string   `json:"id"`
+	DirectStreamIDs []string `json:"directStreamIds"`
+	TimestampUsec   string   `json:"timestampUsec"`
+}
+
+// greaderStream is a parsed stream id, the Google Reader way to say which
+// posts a request is about.
+type greaderStream struct {
+	feed    string
+	folder  string
+	starred bool
+	read    bool
+}
+
+// GReaderKey is what ClientLogin hands a client that logs in with username
+// and password, and what the client then authenticates with.
+func GReaderKey(username, password string) string {
+	sum := sha256.Sum256([]byte(username + ":" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+func (srv *Server) greaderRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/accounts/ClientLogin", srv.handleGReaderLogin)
+
+	const prefix = "/reader/api/0/"
+	mux.HandleFunc("GET "+prefix+"token", srv.greaderAuthenticated(srv.handleGReaderToken))
+	mux.HandleFunc("GET "+prefix+"user-info", srv.greaderAuthenticated(srv.handleGReaderUserInfo))
+	mux.HandleFunc("GET "+prefix+"subscription/list", srv.greaderAuthenticated(srv.handleGReaderSubscriptions))
+	mux.HandleFunc("POST "+prefix+"subscription/edit", srv.greaderAuthenticated(srv.handleGReaderEditSubscription))
+	mux.HandleFunc("GET "+prefix+"tag/list", srv.greaderAuthenticated(srv.handleGReaderTags))
+	mux.HandleFunc("GET "+prefix+"stream/items/ids", srv.greaderAuthenticated(srv.handleGReaderItemIDs))
+	mux.HandleFunc(prefix+"stream/items/contents", srv.greaderAuthenticated(srv.handleGReaderItemContents))
+	mux.HandleFunc("GET "+prefix+"stream/contents/{stream...}", srv.greaderAuthenticated(srv.handleGReaderStreamContents))
+	mux.HandleFunc("POST "+prefix+"edit-tag", srv.greaderAuthenticated(srv.handleGReaderEditTag))
+	mux.HandleFunc("POST "+prefix+"mark-all-as-read", srv.greaderAuthenticated(srv.handleGReaderMarkAllRead))
+}
+
+// greaderAuthenticated is authenticated for the "Authorization: GoogleLogin
+// auth=<key>" header GReader clients send.
+func (srv *Server) greaderAuthenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "GoogleLogin auth=")
+		if !ok || key == "" {
+			respondError(w, http.StatusUnauthorized, "missing GoogleLogin auth header")
+			return
+		}
+
+		user, err := srv.Db.GetUserByAPIToken(r.Context(),
+			database.GetUserByAPITokenParams{
+				TokenHash: HashToken(key),
+				Scope:     ScopeGReader,
+			},
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(w, http.StatusUnauthorized, "invalid auth")
+			return
+		}
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+func (srv *Server) handleGReaderLogin(w http.ResponseWriter, r *http.Request) {
+	username := r.FormValue("Email")
+	key := GReaderKey(username, r.FormValue("Passwd"))
+
+	user, err := srv.Db.GetUserByAPIToken(r.Context(),
+		database.GetUserByAPITokenParams{
+			TokenHash: HashToken(key),
+			Scope:     ScopeGReader,
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user.Name != username) {
+		respondError(w, http.StatusUnauthorized, "invalid username or password")
+		return
+	}
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "SID=%s\nLSID=%s\nAuth=%s\n", key, key, key)
+}
+
+// handleGReaderToken hands out the edit token clients send back as T. It isn't
+// checked, every request already carries the auth header.
+func (srv *Server) handleGReaderToken(w http.ResponseWriter, r *http.Request, user database.User) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, user.ID)
+}
+
+func (srv *Server) handleGReaderUserInfo(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondJSON(w, http.StatusOK, map[string]string{
+		"userId":        user.ID.String(),
+		"userName":      user.Name,
+		"userProfileId": user.ID.String(),
+		"userEmail":     "",
+	})
+}
+
+func (srv *Server) handleGReaderSubscriptions(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := srv.Db.GetFollowedFeeds(r.Context(), user.ID)
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+
+	subscriptions := make([]greaderSubscription, 0, len(feeds))
+	for _, feed := range feeds {
+		sub := greaderSubscription{
+			ID:         greaderFeedID(feed.Seq),
+			Title:      feed.Name,
+			Categories: []greaderCategory{},
+			URL:        feed.Url,
+			HTMLURL:    feed.SiteUrl,
+		}
+		if feed.Folder != "" {
+			sub.Categories = append(sub.Categories, greaderCategory{
+				ID:    greaderLabelPrefix + feed.Folder,
+				Label: feed.Folder,
+			})
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"subscriptions": subscriptions})
+}
+
+func (srv *Server) handleGReaderTags(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := srv.Db.GetFollowedFeeds(r.Context(), user.ID)
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+
+	folders := make(map[string]bool)
+	for _, feed := range feeds {
+		if feed.Folder != "" {
+			folders[feed.Folder] = true
+		}
+	}
+	labels := make([]string, 0, len(folders))
+	for folder := range folders {
+		labels = append(labels, folder)
+	}
+	sort.Strings(labels)
+
+	tags := []greaderTag{{ID: greaderStarred}}
+	for _, label := range labels {
+		tags = append(tags, greaderTag{ID: greaderLabelPrefix + label, Type: "folder"})
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+// handleGReaderEditSubscription takes ac=subscribe|unsubscribe|edit, the
+// feeds as s and labels to add and remove as a and r. Titles belong to the
+// feed everyone shares, so renames with t are only used for new feeds.
+func (srv *Server) handleGReaderEditSubscription(w http.ResponseWriter, r *http.Request, user database.User) {
+	var addFolder, removeFolder string
+	if a := r.Form.Get("a"); a != "" {
+		stream, err := parseGReaderStream(a)
+		if err != nil || stream.folder == "" {
+			respondError(w, http.StatusBadRequest, "a must be a label")
+			return
+		}
+		addFolder = stream.folder
+	}
+	if rm := r.Form.Get("r"); rm != "" {
+		stream, err := parseGReaderStream(rm)
+		if err != nil || stream.folder == "" {
+			respondError(w, http.StatusBadRequest, "r must be a label")
+			return
+		}
+		removeFolder = stream.folder
+	}
+
+	for _, s := range r.Form["s"] {
+		stream, err := parseGReaderStream(s)
+		if err != nil || stream.feed == "" {
+			respondError(w, http.StatusBadRequest, "s must be a feed")
+			return
+		}
+
+		if r.Form.Get("ac") == "subscribe" {
+			err = srv.greaderSubscribe(r, user, stream.feed, r.Form.Get("t"), addFolder)
+			if err != nil {
+				respondInternalError(w, err)
+				return
+			}
+			continue
+		}
+
+		feed, err := srv.greaderFollowedFeed(r, user, stream.feed)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(w, http.StatusNotFound, "not subscribed to "+s)
+			return
+		}
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+
+		switch r.Form.Get("ac") {
+		case "unsubscribe":
+			_, err = srv.Db.DeleteFeedFollow(r.Context(),
+				database.DeleteFeedFollowParams{
+					UserID: user.ID,
+					FeedID: feed.ID,
+				},
+			)
+		case "edit":
+			folder := feed.Folder
+			if removeFolder != "" && removeFolder == folder {
+				folder = ""
+			}
+			if addFolder != "" {
+				folder = addFolder
+			}
+			err = srv.Db.SetFeedFollowFolder(r.Context(),
+				database.SetFeedFollowFolderParams{
+					UserID: user.ID,
+					FeedID: feed.ID,
+					Folder: folder,
+				},
+			)
+		default:
+			respondError(w, http.StatusBadRequest, "ac must be subscribe, unsubscribe or edit")
+			return
+		}
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+	}
+
+	respondOK(w)
+}
+
+// greaderSubscribe follows the feed at feedURL, adding it first if nobody
+// has yet.
+func (srv *Server) greaderSubscribe(r *http.Request, user database.User, feedURL, title, folder string) error {
+	feed, err := srv.Db.GetFeedByURL(r.Context(), feedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		if title == "" {
+			title = feedURL
+		}
+		feed, err = srv.Db.CreateFeed(r.Context(),
+			database.CreateFeedParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				Name:      title,
+				Url:       feedURL,
+				UserID:    user.ID,
+			},
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = srv.Db.CreateFeedFollow(r.Context(),
+		database.CreateFeedFollowParams{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			FeedID: feed.ID,
+			Folder: folder,
+		},
+	)
+	if isUniqueViolation(err) {
+		return nil
+	}
+	return err
+}
+
+// greaderFollowedFeed finds one of the user's feeds by the seq GReader knows
+// it by, or by its url for clients that subscribed with one.
+func (srv *Server) greaderFollowedFeed(r *http.Request, user database.User, id string) (database.GetFollowedFeedsRow, error) {
+	feeds, err := srv.Db.GetFollowedFeeds(r.Context(), user.ID)
+	if err != nil {
+		return database.GetFollowedFeedsRow{}, err
+	}
+	for _, feed := range feeds {
+		if strconv.FormatInt(feed.Seq, 10) == id || feed.Url == id {
+			return feed, nil
+		}
+	}
+	return database.GetFollowedFeedsRow{}, sql.ErrNoRows
+}
+
+// greaderItems lists the items of the stream s, taking the usual n (count),
+// r=o (oldest first), c (continuation), xt and it (exclude or include a
+// state), ot and nt (newer and older than) parameters.
+func (srv *Server) greaderItems(r *http.Request, user database.User, s string, maxCount int) ([]database.GetGReaderItemsRow, string, error) {
+	params := database.GetGReaderItemsParams{
+		UserID:    user.ID,
+		Seqs:      []int64{},
+		Ascending: r.Form.Get("r") == "o",
+	}
+
+	stream, err := parseGReaderStream(s)
+	if err != nil {
+		return nil, "", err
+	}
+	params.Folder = stream.folder
+	params.StarredOnly = stream.starred
+	params.ReadOnly = stream.read
+	if stream.feed != "" {
+		feed, err := srv.greaderFollowedFeed(r, user, stream.feed)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", fmt.Errorf("not subscribed to %s", s)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		params.FeedSeq = feed.Seq
+	}
+
+	for _, include := range r.Form["it"] {
+		switch include {
+		case greaderStarred:
+			params.StarredOnly = true
+		case greaderRead:
+			params.ReadOnly = true
+		}
+	}
+	for _, exclude := range r.Form["xt"] {
+		switch exclude {
+		case greaderStarred:
+			params.ExcludeStarred = true
+		case greaderRead:
+			params.ExcludeRead = true
+		}
+	}
+
+	count, err := intParam(r.Form.Get("n"), greaderDefaultCount)
+	if err != nil || count < 1 {
+		return nil, "", errors.New("n must be a positive number")
+	}
+	count = min(count, maxCount)
+	// fetch one extra item to know if there is a continuation
+	params.Limit = int32(count + 1)
+
+	if c := r.Form.Get("c"); c != "" {
+		params.AfterSeq, err = strconv.ParseInt(c, 10, 64)
+		if err != nil {
+			return nil, "", errors.New("invalid continuation")
+		}
+	}
+	params.NewerThan, err = unixParam(r.Form.Get("ot"))
+	if err != nil {
+		return nil, "", err
+	}
+	params.OlderThan, err = unixParam(r.Form.Get("nt"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	items, err := srv.Db.GetGReaderItems(r.Context(), params)
+	if err != nil {
+		return nil, "", err
+	}
+	var continuation string
+	if len(items) > count {
+		items = items[:count]
+		continuation = strconv.FormatInt(items[count-1].Seq, 10)
+	}
+	return items, continuation, nil
+}
+
+func (srv *Server) handleGReaderItemIDs(w http.ResponseWriter, r *http.Request, user database.User) {
+	items, continuation, err := srv.greaderItems(r, user, r.Form.Get("s"), greaderMaxIDs)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	refs := make([]greaderItemRef, 0, len(items))
+	for _, item := range items {
+		refs = append(refs, greaderItemRef{
+			ID:              strconv.FormatInt(item.Seq, 10),
+			DirectStreamIDs: []string{},
+			TimestampUsec:   strconv.FormatInt(item.PublishedAt.UnixMicro(), 10),
+		})
+	}
+	resp := map[string]any{"itemRefs": refs}
+	if continuation != "" {
+		resp["continuation"] = continuation
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (srv *Server) handleGReaderStreamContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	s := r.PathValue("stream")
+	if s == "" {
+		s = r.Form.Get("s")
+	}
+	items, continuation, err := srv.greaderItems(r, user, s, greaderMaxContents)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := map[string]any{
+		"direction": "ltr",
+		"id":        s,
+		"updated":   time.Now().Unix(),
+		"items":     greaderItemsFromDB(items),
+	}
+	if continuation != "" {
+		resp["continuation"] = continuation
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// handleGReaderItemContents returns the items listed as i, in either the long
+// tag:google.com form or as plain numbers.
+func (srv *Server) handleGReaderItemContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	seqs, err := parseGReaderItemIDs(r.Form["i"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var items []database.GetGReaderItemsRow
+	if len(seqs) > 0 {
+		items, err = srv.Db.GetGReaderItems(r.Context(),
+			database.GetGReaderItemsParams{
+				UserID: user.ID,
+				Seqs:   seqs,
+				Limit:  int32(len(seqs)),
+			},
+		)
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"direction": "ltr",
+		"id":        greaderReadingList,
+		"updated":   time.Now().Unix(),
+		"items":     greaderItemsFromDB(items),
+	})
+}
+
+// handleGReaderEditTag adds (a) or removes (r) the read and starred states
+// of the items listed as i.
+func (srv *Server) handleGReaderEditTag(w http.ResponseWriter, r *http.Request, user database.User) {
+	seqs, err := parseGReaderItemIDs(r.Form["i"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var changes []string
+	for _, tag := range r.Form["a"] {
+		switch tag {
+		case greaderRead:
+			changes = append(changes, "read")
+		case greaderStarred:
+			changes = append(changes, "saved")
+		}
+	}
+	for _, tag := range r.Form["r"] {
+		switch tag {
+		case greaderRead:
+			changes = append(changes, "unread")
+		case greaderStarred:
+			changes = append(changes, "unsaved")
+		}
+	}
+
+	for _, seq := range seqs {
+		postID, err := srv.Db.GetFollowedPostID(r.Context(),
+			database.GetFollowedPostIDParams{
+				Seq:    seq,
+				UserID: user.ID,
+			},
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(w, http.StatusNotFound, fmt.Sprintf("no item %d", seq))
+			return
+		}
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+		for _, change := range changes {
+			err = srv.setPostState(r, user, postID, change)
+			if err != nil {
+				respondInternalError(w, err)
+				return
+			}
+		}
+	}
+
+	respondOK(w)
+}
+
+// handleGReaderMarkAllRead marks the stream s read up to ts, in microseconds.
+func (srv *Server) handleGReaderMarkAllRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	stream, err := parseGReaderStream(r.Form.Get("s"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	before := time.Now().UTC()
+	if ts := r.Form.Get("ts"); ts != "" {
+		usec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid ts")
+			return
+		}
+		before = time.UnixMicro(usec).UTC()
+	}
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+
+	if stream.feed == "" && stream.folder == "" {
+		if stream.starred || stream.read {
+			respondError(w, http.StatusBadRequest, "only feeds, labels and the reading list can be marked read")
+			return
+		}
+		_, err = srv.Db.SetPostsReadBefore(r.Context(),
+			database.SetPostsReadBeforeParams{
+				UserID:      user.ID,
+				Read:        true,
+				ReadAt:      now,
+				PublishedAt: before,
+			},
+		)
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+		respondOK(w)
+		return
+	}
+
+	feeds, err := srv.Db.GetFollowedFeeds(r.Context(), user.ID)
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+	for _, feed := range feeds {
+		inStream := stream.folder != "" && feed.Folder == stream.folder
+		if stream.feed != "" {
+			inStream = strconv.FormatInt(feed.Seq, 10) == stream.feed || feed.Url == stream.feed
+		}
+		if !inStream {
+			continue
+		}
+		_, err = srv.Db.SetFeedPostsReadBefore(r.Context(),
+			database.SetFeedPostsReadBeforeParams{
+				UserID:      user.ID,
+				ReadAt:      now,
+				FeedID:      feed.ID,
+				PublishedAt: before,
+			},
+		)
+		if err != nil {
+			respondInternalError(w, err)
+			return
+		}
+	}
+	respondOK(w)
+}
+
+func greaderItemsFromDB(rows []database.GetGReaderItemsRow) []greaderItem {
+	items := make([]greaderItem, 0, len(rows))
+	for _, row := range rows {
+		categories := []string{greaderReadingList}
+		if row.Folder != "" {
+			categories = append(categories, greaderLabelPrefix+row.Folder)
+		}
+		if row.Read {
+			categories = append(categories, greaderRead)
+		}
+		if row.Starred {
+			categories = append(categories, greaderStarred)
+		}
+
+		items = append(items, greaderItem{
+			ID:            fmt.Sprintf("%s%016x", greaderItemPrefix, row.Seq),
+			CrawlTimeMsec: strconv.FormatInt(row.CreatedAt.UnixMilli(), 10),
+			TimestampUsec: strconv.FormatInt(row.PublishedAt.UnixMicro(), 10),
+			Published:     row.PublishedAt.Unix(),
+			Updated:       row.PublishedAt.Unix(),
+			Title:         row.Title,
+			Canonical:     []greaderLink{{Href: row.Url}},
+			Alternate:     []greaderLink{{Href: row.Url, Type: "text/html"}},
+			Summary:       greaderContent{Direction: "ltr", Content: row.Description},
+			Categories:    categories,
+			Origin: greaderOrigin{
+				StreamID: greaderFeedID(row.FeedSeq),
+				Title:    row.FeedName,
+				HTMLURL:  row.FeedUrl,
+			},
+		})
+	}
+	return items
+}
+
+func greaderFeedID(seq int64) string {
+	return greaderFeedPrefix + strconv.FormatInt(seq, 10)
+}
+
+// parseGReaderStream understands the reading list, the read and starred
+// states, labels and feeds. Clients may send user/<id>/ instead of user/-/.
+func parseGReaderStream(id string) (greaderStream, error) {
+	if rest, ok := strings.CutPrefix(id, "user/"); ok {
+		if _, state, found := strings.Cut(rest, "/"); found {
+			id = "user/-/" + state
+		}
+	}
+
+	switch {
+	case id == "" || id == greaderReadingList:
+		return greaderStream{}, nil
+	case id == greaderStarred:
+		return greaderStream{starred: true}, nil
+	case id == greaderRead:
+		return greaderStream{read: true}, nil
+	case strings.HasPrefix(id, greaderLabelPrefix):
+		return greaderStream{folder: strings.TrimPrefix(id, greaderLabelPrefix)}, nil
+	case strings.HasPrefix(id, greaderFeedPrefix):
+		return greaderStream{feed: strings.TrimPrefix(id, greaderFeedPrefix)}, nil
+	default:
+		return greaderStream{}, fmt.Errorf("unknown stream %s", id)
+	}
+}
+
+func parseGReaderItemIDs(ids []string) ([]int64, error) {
+	seqs := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		var seq int64
+		var err error
+		if hexID, ok := strings.CutPrefix(id, greaderItemPrefix); ok {
+			seq, err = strconv.ParseInt(hexID, 16, 64)
+		} else {
+			seq, err = strconv.ParseInt(id, 10, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid item id %s", id)
+		}
+		seqs = append(seqs, seq)
+	}
+	return seqs, nil
+}
+
+// unixParam parses a time given in seconds since the epoch, if there is one.
+func unixParam(value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return sql.NullTime{}, fmt.Errorf("invalid time %s", value)
+	}
+	return sql.NullTime{Time: time.Unix(unix, 0).UTC(), Valid: true}, nil
+}
+
+func respondOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "OK")
+}
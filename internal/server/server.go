@@ -0,0 +1,161 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/quanchobi/gator/internal/database"
+)
+
+// Server exposes gator's database over a JSON API.
+type Server struct {
+	Db *database.Queries
+}
+
+func New(db *database.Queries) *Server {
+	return &Server{Db: db}
+}
+
+// Handler returns the routes of the API. Every /api route needs an
+// "Authorization: Bearer <token>" header with a token made by "gator token create",
+// except that the feeds also take a feed token.
+func (srv *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/me", srv.authenticated(srv.handleMe))
+	mux.HandleFunc("GET /api/users", srv.authenticated(srv.handleListUsers))
+	// users are created with gator register, a token can't make more
+	mux.HandleFunc("GET /api/feeds", srv.authenticated(srv.handleListFeeds))
+	mux.HandleFunc("POST /api/feeds", srv.authenticated(srv.handleCreateFeed))
+	mux.HandleFunc("GET /api/follows", srv.authenticated(srv.handleListFollows))
+	mux.HandleFunc("POST /api/follows", srv.authenticated(srv.handleFollow))
+	mux.HandleFunc("DELETE /api/follows/{feedID}", srv.authenticated(srv.handleUnfollow))
+	mux.HandleFunc("GET /api/posts", srv.authenticated(srv.handleListPosts))
+	// feed readers can't send headers, so these also take a feed token as ?token=
+	mux.HandleFunc("GET /api/feed.rss", srv.feedAuthenticated(srv.handleFeed("rss")))
+	mux.HandleFunc("GET /api/feed.atom", srv.feedAuthenticated(srv.handleFeed("atom")))
+
+	// Fever authenticates with its own api_key parameter
+	mux.HandleFunc("/fever/", srv.handleFever)
+	// and GReader with a GoogleLogin header
+	srv.greaderRoutes(mux)
+
+	return mux
+}
+
+func (srv *Server) ListenAndServe(addr string) error {
+	log.Printf("serving the gator API on %s", addr)
+	return http.ListenAndServe(addr, srv.Handler())
+}
+
+// authenticated resolves the bearer token of a request to its user, the
+// server's equivalent of the cli's MiddlewareLoggedIn.
+func (srv *Server) authenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			respondError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+		srv.withTokenUser(w, r, token, ScopeAPI, handler)
+	}
+}
+
+// feedAuthenticated is authenticated for the published feeds, which also
+// take a token made by "gator token feed" in the token query parameter. That
+// token can only read the feeds.
+func (srv *Server) feedAuthenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	bearer := srv.authenticated(handler)
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			bearer(w, r)
+			return
+		}
+		srv.withTokenUser(w, r, token, ScopeFeed, handler)
+	}
+}
+
+// withTokenUser calls handler with the user of token, if it has scope.
+func (srv *Server) withTokenUser(w http.ResponseWriter, r *http.Request, token, scope string, handler func(w http.ResponseWriter, r *http.Request, user database.User)) {
+	user, err := srv.Db.GetUserByAPIToken(r.Context(),
+		database.GetUserByAPITokenParams{
+			TokenHash: HashToken(token),
+			Scope:     scope,
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+	if err != nil {
+		respondInternalError(w, err)
+		return
+	}
+
+	handler(w, r, user)
+}
+
+// Token scopes keep the keys of the client APIs, which are derived from a
+// password, and feed tokens, which end up in urls, from opening the JSON API.
+const (
+	ScopeAPI     = "api"
+	ScopeFever   = "fever"
+	ScopeGReader = "greader"
+	ScopeFeed    = "feed"
+)
+
+// NewToken makes a random API token. Only its hash is stored, the token itself
+// is shown to the user once.
+func NewToken() (token string, hash string, err error) {
+	raw := make([]byte, 32)
+	_, err = rand.Read(raw)
+	if err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(raw)
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func respondJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(payload)
+	if err != nil {
+		log.Printf("error writing response: %v", err)
+	}
+}
+
+func respondError(w http.ResponseWriter, status int, message string) {
+	respondJSON(w, status, map[string]string{"error": message})
+}
+
+func respondInternalError(w http.ResponseWriter, err error) {
+	log.Printf("internal error: %v", err)
+	respondError(w, http.StatusInternalServerError, "internal error")
+}
+
+// decodeJSON reads the request body into v, answering with 400 if it can't.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,438 @@
+// Package tui is a three pane terminal reader: followed feeds, their posts
+// and the selected post.
+package tui
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+)
+
+// postLimit is how many posts a feed shows, newest first.
+const postLimit = 200
+
+type pane int
+
+const (
+	feedsPane pane = iota
+	postsPane
+	bodyPane
+)
+
+// feedItem is an entry of the feeds pane. The first one, with no id, is every
+// followed feed at once.
+type feedItem struct {
+	id     uuid.NullUUID
+	name   string
+	folder string
+}
+
+type model struct {
+	db      *database.Queries
+	user    database.User
+	refresh time.Duration
+
+	feeds      []feedItem
+	feedCursor int
+	posts      []database.GetPostsForUserRow
+	postCursor int
+	showRead   bool
+
+	focus  pane
+	body   viewport.Model
+	width  int
+	height int
+	status string
+}
+
+type feedsMsg []database.GetFeedFollowsForUserRow
+
+type postsMsg struct {
+	feed  uuid.NullUUID
+	posts []database.GetPostsForUserRow
+}
+
+type tickMsg time.Time
+
+type statusMsg string
+
+type errMsg struct{ err error }
+
+// readErrMsg and starErrMsg are errors saving a post's state, which was
+// already shown and is put back to read or starred.
+type readErrMsg struct {
+	id   uuid.UUID
+	read bool
+	err  error
+}
+
+type starErrMsg struct {
+	id      uuid.UUID
+	starred bool
+	err     error
+}
+
+// Run starts the reader for user and blocks until it is quit. Posts are
+// reloaded from the database every refresh, so a running agg shows up live.
+func Run(db *database.Queries, user database.User, refresh time.Duration) error {
+	m := model{
+		db:      db,
+		user:    user,
+		refresh: refresh,
+		feeds:   []feedItem{{name: "All feeds"}},
+		body:    viewport.New(0, 0),
+	}
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.loadFeeds(), m.loadPosts(), tick(m.refresh))
+}
+
+func tick(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func (m model) loadFeeds() tea.Cmd {
+	return func() tea.Msg {
+		follows, err := m.db.GetFeedFollowsForUser(context.Background(), m.user.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		return feedsMsg(follows)
+	}
+}
+
+func (m model) loadPosts() tea.Cmd {
+	feed := m.feeds[m.feedCursor].id
+	return func() tea.Msg {
+		posts, err := m.db.GetPostsForUser(context.Background(),
+			database.GetPostsForUserParams{
+				UserID:      m.user.ID,
+				IncludeRead: m.showRead,
+				FeedID:      feed,
+				Limit:       postLimit,
+			},
+		)
+		if err != nil {
+			return errMsg{err}
+		}
+		return postsMsg{feed: feed, posts: posts}
+	}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.body.Width, m.body.Height = m.bodySize()
+		m.setBody()
+		return m, nil
+
+	case feedsMsg:
+		selected := m.feeds[m.feedCursor].id
+		m.setFeeds(msg)
+		// the selected feed was unfollowed, show all of them instead
+		if m.feeds[m.feedCursor].id != selected {
+			m.posts = nil
+			return m, m.loadPosts()
+		}
+		return m, nil
+
+	case postsMsg:
+		// a reply for a feed that is no longer selected
+		if msg.feed != m.feeds[m.feedCursor].id {
+			return m, nil
+		}
+		m.setPosts(msg.posts)
+		return m, nil
+
+	case tickMsg:
+		return m, tea.Batch(m.loadFeeds(), m.loadPosts(), tick(m.refresh))
+
+	case statusMsg:
+		m.status = string(msg)
+		return m, nil
+
+	case errMsg:
+		m.status = "error: " + msg.err.Error()
+		return m, nil
+
+	case readErrMsg:
+		m.status = "error: " + msg.err.Error()
+		if i, ok := m.postIndex(msg.id); ok {
+			m.posts[i].Read = msg.read
+		}
+		return m, nil
+
+	case starErrMsg:
+		m.status = "error: " + msg.err.Error()
+		if i, ok := m.postIndex(msg.id); ok {
+			m.posts[i].Starred = msg.starred
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "tab", "l", "right":
+		m.focus = min(m.focus+1, bodyPane)
+		return m, nil
+	case "shift+tab", "h", "left":
+		m.focus = max(m.focus-1, feedsPane)
+		return m, nil
+	case "a":
+		m.showRead = !m.showRead
+		if m.showRead {
+			m.status = "showing read posts"
+		} else {
+			m.status = "hiding read posts"
+		}
+		return m, m.loadPosts()
+	case "R", "ctrl+r":
+		m.status = "refreshing"
+		return m, tea.Batch(m.loadFeeds(), m.loadPosts())
+	}
+
+	switch m.focus {
+	case feedsPane:
+		switch msg.String() {
+		case "j", "down":
+			return m.selectFeed(m.feedCursor + 1)
+		case "k", "up":
+			return m.selectFeed(m.feedCursor - 1)
+		case "enter":
+			m.focus = postsPane
+		}
+		return m, nil
+
+	case postsPane:
+		switch msg.String() {
+		case "j", "down":
+			m.selectPost(m.postCursor + 1)
+		case "k", "up":
+			m.selectPost(m.postCursor - 1)
+		case "enter":
+			m.focus = bodyPane
+			return m, m.setRead(true)
+		}
+	}
+
+	switch msg.String() {
+	case "r":
+		post, ok := m.selectedPost()
+		if ok {
+			return m, m.setRead(!post.Read)
+		}
+		return m, nil
+	case "s":
+		return m, m.toggleStar()
+	case "o":
+		post, ok := m.selectedPost()
+		if ok {
+			return m, tea.Batch(m.setRead(true), openURL(post.Url))
+		}
+		return m, nil
+	}
+
+	if m.focus == bodyPane {
+		var cmd tea.Cmd
+		m.body, cmd = m.body.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// setFeeds replaces the followed feeds, keeping the selected one selected.
+func (m *model) setFeeds(follows []database.GetFeedFollowsForUserRow) {
+	selected := m.feeds[m.feedCursor].id
+
+	sort.Slice(follows, func(i, j int) bool {
+		if follows[i].Folder != follows[j].Folder {
+			return follows[i].Folder < follows[j].Folder
+		}
+		return strings.ToLower(follows[i].Feedname) < strings.ToLower(follows[j].Feedname)
+	})
+	m.feeds = []feedItem{{name: "All feeds"}}
+	m.feedCursor = 0
+	for _, follow := range follows {
+		id := uuid.NullUUID{UUID: follow.FeedID, Valid: true}
+		if id == selected {
+			m.feedCursor = len(m.feeds)
+		}
+		m.feeds = append(m.feeds, feedItem{
+			id:     id,
+			name:   follow.Feedname,
+			folder: follow.Folder,
+		})
+	}
+}
+
+// setPosts replaces the listed posts, keeping the selected one selected if it
+// is still there.
+func (m *model) setPosts(posts []database.GetPostsForUserRow) {
+	selected, hadSelection := m.selectedPost()
+	m.posts = posts
+	m.postCursor = min(m.postCursor, max(len(posts)-1, 0))
+	if hadSelection {
+		if i, ok := m.postIndex(selected.ID); ok {
+			m.postCursor = i
+		}
+	}
+
+	current, ok := m.selectedPost()
+	if !ok || !hadSelection || current.ID != selected.ID {
+		m.body.GotoTop()
+	}
+	m.setBody()
+}
+
+func (m model) selectFeed(i int) (tea.Model, tea.Cmd) {
+	if i < 0 || i >= len(m.feeds) || i == m.feedCursor {
+		return m, nil
+	}
+	m.feedCursor = i
+	m.posts = nil
+	m.postCursor = 0
+	m.setBody()
+	return m, m.loadPosts()
+}
+
+func (m *model) selectPost(i int) {
+	if i < 0 || i >= len(m.posts) || i == m.postCursor {
+		return
+	}
+	m.postCursor = i
+	m.body.GotoTop()
+	m.setBody()
+}
+
+func (m model) selectedPost() (database.GetPostsForUserRow, bool) {
+	if m.postCursor >= len(m.posts) {
+		return database.GetPostsForUserRow{}, false
+	}
+	return m.posts[m.postCursor], true
+}
+
+// postIndex finds the listed post with id, which may have moved since a
+// command for it was started.
+func (m model) postIndex(id uuid.UUID) (int, bool) {
+	for i, post := range m.posts {
+		if post.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// setRead marks the selected post read or unread. The post stays listed until
+// the next refresh, even when read posts are hidden. It is shown changed right
+// away, and changed back if saving it fails.
+func (m *model) setRead(read bool) tea.Cmd {
+	post, ok := m.selectedPost()
+	if !ok || post.Read == read {
+		return nil
+	}
+	m.posts[m.postCursor].Read = read
+
+	db, userID := m.db, m.user.ID
+	return func() tea.Msg {
+		err := db.SetPostRead(context.Background(),
+			database.SetPostReadParams{
+				UserID: userID,
+				PostID: post.ID,
+				Read:   read,
+				ReadAt: sql.NullTime{
+					Time:  time.Now(),
+					Valid: read,
+				},
+			},
+		)
+		if err != nil {
+			return readErrMsg{id: post.ID, read: post.Read, err: err}
+		}
+		return nil
+	}
+}
+
+// toggleStar stars or unstars the selected post, shown like setRead.
+func (m *model) toggleStar() tea.Cmd {
+	post, ok := m.selectedPost()
+	if !ok {
+		return nil
+	}
+	starred := !post.Starred
+	m.posts[m.postCursor].Starred = starred
+
+	db, userID := m.db, m.user.ID
+	return func() tea.Msg {
+		var err error
+		if starred {
+			err = db.StarPost(context.Background(),
+				database.StarPostParams{
+					UserID:    userID,
+					PostID:    post.ID,
+					CreatedAt: time.Now(),
+				},
+			)
+		} else {
+			_, err = db.UnstarPost(context.Background(),
+				database.UnstarPostParams{
+					UserID: userID,
+					PostID: post.ID,
+				},
+			)
+		}
+		if err != nil {
+			return starErrMsg{id: post.ID, starred: post.Starred, err: err}
+		}
+		if starred {
+			return statusMsg("starred " + post.Title)
+		}
+		return statusMsg("unstarred " + post.Title)
+	}
+}
+
+// openURL opens url in $BROWSER, or the system's default browser.
+func openURL(url string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch {
+		case os.Getenv("BROWSER") != "":
+			cmd = exec.Command(os.Getenv("BROWSER"), url)
+		case runtime.GOOS == "darwin":
+			cmd = exec.Command("open", url)
+		case runtime.GOOS == "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+		default:
+			cmd = exec.Command("xdg-open", url)
+		}
+		err := cmd.Start()
+		if err != nil {
+			return errMsg{err}
+		}
+		// don't leave a zombie behind
+		go cmd.Wait()
+		return statusMsg("opened " + url)
+	}
+}
@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/google/uuid"
+	"github.com/quanchobi/gator/internal/database"
+)
+
+func newModel() model {
+	return model{
+		feeds: []feedItem{{name: "All feeds"}},
+		body:  viewport.New(20, 10),
+	}
+}
+
+func follow(id uuid.UUID, name, folder string) database.GetFeedFollowsForUserRow {
+	return database.GetFeedFollowsForUserRow{FeedID: id, Feedname: name, Folder: folder}
+}
+
+func TestSetFeeds(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		name     string
+		selected uuid.UUID
+		follows  []database.GetFeedFollowsForUserRow
+		want     []string
+		cursor   int
+	}{
+		{
+			name:    "sorted by folder then name",
+			follows: []database.GetFeedFollowsForUserRow{follow(a, "zed", ""), follow(b, "Blog", "tech"), follow(c, "alpha", "")},
+			want:    []string{"All feeds", "alpha", "zed", "Blog"},
+		},
+		{
+			name:     "selection follows the feed",
+			selected: b,
+			follows:  []database.GetFeedFollowsForUserRow{follow(a, "b", ""), follow(b, "c", ""), follow(c, "a", "")},
+			want:     []string{"All feeds", "a", "b", "c"},
+			cursor:   3,
+		},
+		{
+			name:     "unfollowed selection falls back to all feeds",
+			selected: b,
+			follows:  []database.GetFeedFollowsForUserRow{follow(a, "a", "")},
+			want:     []string{"All feeds", "a"},
+		},
+	}
+	for _, tt := range tests {
+		m := newModel()
+		if tt.selected != uuid.Nil {
+			m.feeds = append(m.feeds, feedItem{id: uuid.NullUUID{UUID: tt.selected, Valid: true}})
+			m.feedCursor = 1
+		}
+		m.setFeeds(tt.follows)
+		var names []string
+		for _, feed := range m.feeds {
+			names = append(names, feed.name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") || m.feedCursor != tt.cursor {
+			t.Errorf("%s: got %q at %d, want %q at %d", tt.name, names, m.feedCursor, tt.want, tt.cursor)
+		}
+	}
+}
+
+func TestSetPosts(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	posts := func(ids ...uuid.UUID) []database.GetPostsForUserRow {
+		rows := make([]database.GetPostsForUserRow, len(ids))
+		for i, id := range ids {
+			rows[i] = database.GetPostsForUserRow{ID: id}
+		}
+		return rows
+	}
+	tests := []struct {
+		name   string
+		before []database.GetPostsForUserRow
+		cursor int
+		after  []database.GetPostsForUserRow
+		want   int
+	}{
+		{"first load", nil, 0, posts(ids...), 0},
+		{"selection moved down", posts(ids[0], ids[1]), 1, posts(ids[2], ids[0], ids[1]), 2},
+		{"selection gone keeps the position", posts(ids...), 1, posts(ids[0], ids[2]), 1},
+		{"selection gone past the end", posts(ids...), 2, posts(ids[0]), 0},
+		{"no posts left", posts(ids...), 2, nil, 0},
+	}
+	for _, tt := range tests {
+		m := newModel()
+		m.posts = tt.before
+		m.postCursor = tt.cursor
+		m.setPosts(tt.after)
+		if m.postCursor != tt.want {
+			t.Errorf("%s: cursor = %d, want %d", tt.name, m.postCursor, tt.want)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	lines := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+	tests := []struct {
+		lines  []string
+		cursor int
+		height int
+		want   string
+	}{
+		{lines[:3], 2, 5, "0\n1\n2"},
+		{lines, 0, 4, "0\n1\n2\n3"},
+		// the cursor is kept in the middle
+		{lines, 5, 4, "3\n4\n5\n6"},
+		{lines, 9, 4, "6\n7\n8\n9"},
+		{nil, 0, 4, ""},
+	}
+	for _, tt := range tests {
+		got := window(tt.lines, tt.cursor, tt.height)
+		if got != tt.want {
+			t.Errorf("window(%d lines, %d, %d) = %q, want %q", len(tt.lines), tt.cursor, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestFailedWriteIsUndone(t *testing.T) {
+	id := uuid.New()
+	m := newModel()
+	m.posts = []database.GetPostsForUserRow{{ID: uuid.New()}, {ID: id, Read: true, Starred: true}}
+
+	err := errors.New("connection refused")
+	next, _ := m.Update(readErrMsg{id: id, read: false, err: err})
+	next, _ = next.Update(starErrMsg{id: id, starred: false, err: err})
+	m = next.(model)
+	if post := m.posts[1]; post.Read || post.Starred {
+		t.Errorf("post after failed writes = read %v starred %v, want neither", post.Read, post.Starred)
+	}
+	if !strings.Contains(m.status, "connection refused") {
+		t.Errorf("status = %q, want the error", m.status)
+	}
+}
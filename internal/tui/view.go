@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/quanchobi/gator/internal/render"
+)
+
+var (
+	paneStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("8"))
+	focusedPaneStyle = paneStyle.BorderForeground(lipgloss.Color("12"))
+	selectedStyle    = lipgloss.NewStyle().Reverse(true)
+	dimStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	titleStyle       = lipgloss.NewStyle().Bold(true)
+	statusStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+)
+
+const help = "tab/h/l panes  j/k move  enter open  r read  s star  o browser  a show read  R refresh  q quit"
+
+func (m model) View() string {
+	if m.width == 0 {
+		return "loading..."
+	}
+	feedsWidth, postsWidth, bodyWidth, height := m.layout()
+
+	panes := lipgloss.JoinHorizontal(lipgloss.Top,
+		m.pane(feedsPane, feedsWidth, height, m.feedLines(feedsWidth, height)),
+		m.pane(postsPane, postsWidth, height, m.postLines(postsWidth, height)),
+		m.pane(bodyPane, bodyWidth, height, m.body.View()),
+	)
+
+	status := m.status
+	if status == "" {
+		status = help
+	}
+	return panes + "\n" + statusStyle.Render(ansi.Truncate(status, m.width, "…"))
+}
+
+// layout splits the terminal into the three panes, giving sizes inside their
+// borders.
+func (m model) layout() (feeds, posts, body, height int) {
+	inner := m.width - 6
+	feeds = max(inner/5, 12)
+	posts = max(inner*2/5, 20)
+	body = max(inner-feeds-posts, 10)
+	height = max(m.height-3, 1)
+	return feeds, posts, body, height
+}
+
+func (m model) bodySize() (int, int) {
+	_, _, body, height := m.layout()
+	return body, height
+}
+
+func (m model) pane(p pane, width, height int, content string) string {
+	style := paneStyle
+	if m.focus == p {
+		style = focusedPaneStyle
+	}
+	return style.Width(width).Height(height).MaxHeight(height + 2).Render(content)
+}
+
+func (m model) feedLines(width, height int) string {
+	lines := make([]string, 0, len(m.feeds))
+	for i, feed := range m.feeds {
+		name := feed.name
+		if feed.folder != "" {
+			name = feed.folder + "/" + name
+		}
+		lines = append(lines, m.line(name, width, i == m.feedCursor))
+	}
+	return window(lines, m.feedCursor, height)
+}
+
+func (m model) postLines(width, height int) string {
+	if len(m.posts) == 0 {
+		return dimStyle.Render("no posts")
+	}
+	lines := make([]string, 0, len(m.posts))
+	for i, post := range m.posts {
+		marker := " "
+		switch {
+		case post.Starred:
+			marker = "*"
+		case !post.Read:
+			marker = "•"
+		}
+		title := strings.Join(strings.Fields(post.Title), " ")
+		text := fmt.Sprintf("%s %s %s", marker, post.PublishedAt.Format("Jan 02"), title)
+		lines = append(lines, m.line(text, width, i == m.postCursor))
+	}
+	return window(lines, m.postCursor, height)
+}
+
+func (m model) line(text string, width int, selected bool) string {
+	text = ansi.Truncate(text, width, "…")
+	if selected {
+		return selectedStyle.Render(text + strings.Repeat(" ", max(width-ansi.StringWidth(text), 0)))
+	}
+	return text
+}
+
+// window keeps the cursor's line in view when there are more lines than fit.
+func window(lines []string, cursor, height int) string {
+	start := 0
+	if len(lines) > height {
+		start = min(max(cursor-height/2, 0), len(lines)-height)
+	}
+	end := min(start+height, len(lines))
+	return strings.Join(lines[start:end], "\n")
+}
+
+// setBody shows the selected post in the body pane.
+func (m *model) setBody() {
+	post, ok := m.selectedPost()
+	if !ok {
+		m.body.SetContent("")
+		return
+	}
+	width := max(m.body.Width, 1)
+	wrap := lipgloss.NewStyle().Width(width)
+
+	var b strings.Builder
+	b.WriteString(wrap.Render(titleStyle.Render(post.Title)))
+	b.WriteString("\n")
+	b.WriteString(wrap.Render(dimStyle.Render(post.FeedName + " · " + post.PublishedAt.Format("Mon, 02 Jan 2006 15:04"))))
+	b.WriteString("\n")
+	b.WriteString(wrap.Render(dimStyle.Render(post.Url)))
+	b.WriteString("\n\n")
+	b.WriteString(render.HTML(post.Description, render.Options{
+		Width:   width,
+		Style:   true,
+		BaseURL: post.Url,
+	}))
+	m.body.SetContent(b.String())
+}
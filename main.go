@@ -26,7 +26,10 @@ func main() {
 		}
 	}
 
-	args := os.Args[1:] // first argument will just be go, so we can ignore it
+	output, args, err := cli.ParseOutputFlag(os.Args[1:]) // first argument will just be go, so we can ignore it
+	if err != nil {
+		log.Fatal(err)
+	}
 	if len(args) < 1 {
 		log.Fatal(fmt.Errorf("at least one argument required"))
 	}
@@ -45,8 +48,10 @@ func main() {
 	dbQueries := database.New(pdb)
 
 	state := cli.State{
-		Cfg: &conf,
-		Db:  dbQueries,
+		Cfg:    &conf,
+		Db:     dbQueries,
+		Conn:   pdb,
+		Output: output,
 	}
 
 	err = cmds.Run(&state, command)
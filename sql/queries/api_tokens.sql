@@ -0,0 +1,26 @@
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (id, created_at, user_id, name, token_hash, scope)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetAPITokensForUser :many
+SELECT * FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetUserByAPIToken :one
+SELECT users.* FROM api_tokens
+JOIN users
+ON api_tokens.user_id = users.id
+WHERE api_tokens.token_hash = $1 AND api_tokens.scope = $2;
+
+-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE id = $1 AND user_id = $2;
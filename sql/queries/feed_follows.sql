@@ -1,10 +1,11 @@
 -- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows (id, user_id, feed_id)
+    INSERT INTO feed_follows (id, user_id, feed_id, folder)
     VALUES (
         $1,
         $2,
-        $3
+        $3,
+        $4
     )
     RETURNING *
 ) 
@@ -23,7 +24,9 @@ SELECT feed_follows.id,
     users.name AS username,
     feed_follows.feed_id,
     feeds.name AS feedname,
-    feeds.url AS url
+    feeds.url AS url,
+    feeds.site_url,
+    feed_follows.folder
 FROM feed_follows
 JOIN feeds
 ON feed_follows.feed_id = feeds.id
@@ -31,6 +34,27 @@ JOIN users
 ON feed_follows.user_id = users.id
 WHERE feed_follows.user_id = $1;
 
--- name: DeleteFeedFollow :exec
+-- name: GetFollowedFeeds :many
+-- The feeds a user follows in the order of their seq, which the client APIs
+-- identify them by.
+SELECT feeds.seq,
+    feeds.id,
+    feeds.name,
+    feeds.url,
+    feeds.site_url,
+    feeds.last_fetched_at,
+    feed_follows.folder
+FROM feed_follows
+JOIN feeds
+ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.seq;
+
+-- name: DeleteFeedFollow :execrows
 DELETE FROM feed_follows
     WHERE user_id = $1 AND feed_id = $2;
+
+-- name: SetFeedFollowFolder :exec
+UPDATE feed_follows
+SET folder = $3
+WHERE user_id = $1 AND feed_id = $2;
@@ -19,6 +19,9 @@ SELECT feeds.id,
     feeds.url, 
     feeds.user_id, 
     feeds.last_fetched_at,
+    feeds.failure_count,
+    feeds.last_error,
+    feeds.disabled,
     users.name AS username
 FROM feeds
 JOIN users
@@ -31,10 +34,55 @@ WHERE url = $1;
 -- name: MarkFeedFetched :exec
 UPDATE feeds
 SET last_fetched_at = $2,
-    updated_at = $3
+    updated_at = $3,
+    etag = $4,
+    last_modified = $5,
+    next_fetch_at = $6,
+    update_hint_seconds = $7,
+    failure_count = 0
 WHERE ID = $1;
 
--- name: GetNextFeedToFetch :one
-SELECT * FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
+-- name: ClaimFeedsToFetch :many
+-- Pushes next_fetch_at of the claimed feeds out to lease_until, so other agg
+-- processes skip them until MarkFeedFetched sets the real next fetch time.
+UPDATE feeds
+SET next_fetch_at = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE NOT disabled
+        AND (next_fetch_at IS NULL OR next_fetch_at <= sqlc.arg(now))
+    ORDER BY next_fetch_at ASC NULLS FIRST
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: SetFeedPollInterval :exec
+UPDATE feeds
+SET poll_interval_seconds = $2,
+    next_fetch_at = NULL,
+    updated_at = $3
+WHERE id = $1;
+
+-- name: RecordFeedError :exec
+UPDATE feeds
+SET failure_count = $2,
+    last_error = $3,
+    last_error_at = $4,
+    next_fetch_at = $5,
+    disabled = $6,
+    updated_at = $7
+WHERE id = $1;
+
+-- name: EnableFeed :exec
+UPDATE feeds
+SET disabled = FALSE,
+    failure_count = 0,
+    next_fetch_at = NULL,
+    updated_at = $2
+WHERE id = $1;
+
+-- name: SetFeedSiteURL :exec
+UPDATE feeds
+SET site_url = $2
+WHERE id = $1;
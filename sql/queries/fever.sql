@@ -0,0 +1,65 @@
+-- name: GetFeverItems :many
+-- Fever asks for items after since_seq in ascending order, before max_seq in
+-- descending order, or for a list of seqs. Unused filters are 0 or empty.
+SELECT posts.seq,
+    feeds.seq AS feed_seq,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    COALESCE(post_states.read, FALSE)::boolean AS read,
+    (stars.post_id IS NOT NULL)::boolean AS starred
+FROM posts
+JOIN feeds
+ON posts.feed_id = feeds.id
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id)
+LEFT JOIN stars
+ON stars.post_id = posts.id AND stars.user_id = sqlc.arg(user_id)
+WHERE posts.seq > sqlc.arg(since_seq)::bigint
+    AND (sqlc.arg(max_seq)::bigint = 0 OR posts.seq < sqlc.arg(max_seq)::bigint)
+    AND (cardinality(sqlc.arg(seqs)::bigint[]) = 0 OR posts.seq = ANY(sqlc.arg(seqs)::bigint[]))
+ORDER BY CASE WHEN sqlc.arg(max_seq)::bigint = 0 THEN posts.seq ELSE -posts.seq END
+LIMIT sqlc.arg('limit');
+
+-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1;
+
+-- name: GetUnreadPostSeqs :many
+SELECT posts.seq FROM posts
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+    AND NOT COALESCE(post_states.read, FALSE)
+ORDER BY posts.seq;
+
+-- name: GetStarredPostSeqs :many
+SELECT posts.seq FROM stars
+JOIN posts
+ON stars.post_id = posts.id
+WHERE stars.user_id = $1
+ORDER BY posts.seq;
+
+-- name: GetFollowedPostID :one
+-- The post numbered seq, if it is in a feed the user follows.
+SELECT posts.id FROM posts
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id
+WHERE posts.seq = $1 AND feed_follows.user_id = $2;
+
+-- name: SetFeedPostsReadBefore :execrows
+INSERT INTO post_states (user_id, post_id, read, read_at)
+SELECT $1, posts.id, TRUE, $2
+FROM posts
+WHERE posts.feed_id = $3
+    AND posts.published_at < $4
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = EXCLUDED.read,
+    read_at = EXCLUDED.read_at;
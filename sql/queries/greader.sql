@@ -0,0 +1,38 @@
+-- name: GetGReaderItems :many
+-- Items of a GReader stream, newest first unless ascending. Unused filters
+-- are 0, empty, false or null. Pages continue past after_seq.
+SELECT posts.seq,
+    feeds.seq AS feed_seq,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    feed_follows.folder,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.created_at,
+    COALESCE(post_states.read, FALSE)::boolean AS read,
+    (stars.post_id IS NOT NULL)::boolean AS starred
+FROM posts
+JOIN feeds
+ON posts.feed_id = feeds.id
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id)
+LEFT JOIN stars
+ON stars.post_id = posts.id AND stars.user_id = sqlc.arg(user_id)
+WHERE (cardinality(sqlc.arg(seqs)::bigint[]) = 0 OR posts.seq = ANY(sqlc.arg(seqs)::bigint[]))
+    AND (sqlc.arg(feed_seq)::bigint = 0 OR feeds.seq = sqlc.arg(feed_seq)::bigint)
+    AND (sqlc.arg(folder)::text = '' OR feed_follows.folder = sqlc.arg(folder)::text)
+    AND (NOT sqlc.arg(starred_only)::boolean OR stars.post_id IS NOT NULL)
+    AND (NOT sqlc.arg(read_only)::boolean OR COALESCE(post_states.read, FALSE))
+    AND (NOT sqlc.arg(exclude_read)::boolean OR NOT COALESCE(post_states.read, FALSE))
+    AND (NOT sqlc.arg(exclude_starred)::boolean OR stars.post_id IS NULL)
+    AND (sqlc.narg(newer_than)::timestamp IS NULL OR posts.published_at > sqlc.narg(newer_than)::timestamp)
+    AND (sqlc.narg(older_than)::timestamp IS NULL OR posts.published_at < sqlc.narg(older_than)::timestamp)
+    AND (sqlc.arg(after_seq)::bigint = 0
+        OR (sqlc.arg(ascending)::boolean AND posts.seq > sqlc.arg(after_seq)::bigint)
+        OR (NOT sqlc.arg(ascending)::boolean AND posts.seq < sqlc.arg(after_seq)::bigint))
+ORDER BY CASE WHEN sqlc.arg(ascending)::boolean THEN posts.seq ELSE -posts.seq END
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,35 @@
+-- name: SetPostRead :exec
+INSERT INTO post_states (user_id, post_id, read, read_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = EXCLUDED.read,
+    read_at = EXCLUDED.read_at;
+
+-- name: SetFeedPostsRead :execrows
+INSERT INTO post_states (user_id, post_id, read, read_at)
+SELECT $1, posts.id, $2, $3
+FROM posts
+JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+    AND posts.feed_id = $4
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = EXCLUDED.read,
+    read_at = EXCLUDED.read_at;
+
+-- name: SetPostsReadBefore :execrows
+INSERT INTO post_states (user_id, post_id, read, read_at)
+SELECT $1, posts.id, $2, $3
+FROM posts
+JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+    AND posts.published_at < $4
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = EXCLUDED.read,
+    read_at = EXCLUDED.read_at;
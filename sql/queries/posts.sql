@@ -1,21 +1,123 @@
--- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+-- name: GetPostsForUser :many
+-- Posts from every feed the user follows, whoever added it. The feed, time
+-- range and match filters are skipped when null or empty. Pages continue
+-- after the post at (after_published_at, after_id).
+SELECT posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.published_at_estimated,
+    posts.guid,
+    posts.seq,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    COALESCE(post_states.read, FALSE)::boolean AS read,
+    (stars.post_id IS NOT NULL)::boolean AS starred
+FROM posts
+JOIN feeds
+ON posts.feed_id = feeds.id
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id)
+LEFT JOIN stars
+ON stars.post_id = posts.id AND stars.user_id = sqlc.arg(user_id)
+WHERE (sqlc.arg(include_read)::boolean OR NOT COALESCE(post_states.read, FALSE))
+    AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id)::uuid)
+    AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since)::timestamp)
+    AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until)::timestamp)
+    AND (sqlc.arg(match)::text = ''
+        OR strpos(lower(posts.title), lower(sqlc.arg(match)::text)) > 0
+        OR strpos(lower(posts.description), lower(sqlc.arg(match)::text)) > 0)
+    AND (sqlc.narg(after_published_at)::timestamp IS NULL
+        OR (posts.published_at, posts.id) < (sqlc.narg(after_published_at)::timestamp, sqlc.narg(after_id)::uuid))
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetPostDatesForFeed :many
+SELECT published_at FROM posts
+WHERE feed_id = $1 AND NOT published_at_estimated
+ORDER BY published_at DESC
+LIMIT $2;
+
+-- name: AdoptPostGUID :exec
+-- Gives a post stored before posts had guids the guid of the item at its url,
+-- so the upsert that follows updates it instead of storing it again.
+UPDATE posts
+SET guid = $3
+WHERE feed_id = $1
+    AND url = $2
+    AND guid IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM posts AS adopted
+        WHERE adopted.feed_id = $1 AND adopted.guid = $3
+    );
+
+-- name: UpsertPost :one
+-- Inserts a post, or updates it if the publisher changed it since it was
+-- stored. Returns no rows when the post is already stored unchanged.
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, published_at_estimated, guid)
 VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6, 
+    $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10
 )
-RETURNING *;
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    updated_at = EXCLUDED.updated_at
+WHERE posts.title <> EXCLUDED.title
+    OR posts.url <> EXCLUDED.url
+    OR posts.description <> EXCLUDED.description
+RETURNING (xmax = 0)::boolean AS inserted;
 
--- name: GetPostsForUser :many
-SELECT * FROM posts
+-- name: GetPostByID :one
+-- The post with id, if it is in a feed the user follows.
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_estimated, posts.guid, posts.seq
+FROM posts
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id
+WHERE posts.id = $1 AND feed_follows.user_id = $2;
+
+-- name: GetPostByURL :one
+-- The latest post at url in a feed the user follows.
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_estimated, posts.guid, posts.seq
+FROM posts
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id
+WHERE posts.url = $1 AND feed_follows.user_id = $2
+ORDER BY posts.published_at DESC
+LIMIT 1;
+
+-- name: SearchPostsForUser :many
+SELECT posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(posts.search_vector, query)::real AS rank,
+    ts_headline('english', translate(posts.title || ' ' || posts.description, chr(2) || chr(3), ''), query,
+        'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=2, MaxWords=30, MinWords=10')::text AS snippet
+FROM posts
 JOIN feeds
 ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2;
+JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id,
+websearch_to_tsquery('english', sqlc.arg(query)) query
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND posts.search_vector @@ query
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,35 @@
+-- name: StarPost :exec
+INSERT INTO stars (user_id, post_id, created_at, note)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET note = EXCLUDED.note;
+
+-- name: UnstarPost :execrows
+DELETE FROM stars
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetStarredPostsForUser :many
+SELECT posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.published_at_estimated,
+    posts.guid,
+    posts.seq,
+    stars.note,
+    stars.created_at AS starred_at
+FROM stars
+JOIN posts
+ON stars.post_id = posts.id
+WHERE stars.user_id = $1
+ORDER BY posts.published_at DESC
+LIMIT $2;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN next_fetch_at TIMESTAMP NULL,
+ADD COLUMN poll_interval_seconds INTEGER NULL,
+ADD COLUMN update_hint_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN next_fetch_at,
+DROP COLUMN poll_interval_seconds,
+DROP COLUMN update_hint_seconds;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Posts stored before this have no guid, and their items' ids can't be
+-- recovered from what was stored. The first fetch that still lists them
+-- gives them their guid, see AdoptPostGUID.
+ALTER TABLE posts
+ADD COLUMN guid VARCHAR NULL,
+DROP CONSTRAINT posts_url_key,
+ADD CONSTRAINT posts_feed_id_guid_key UNIQUE (feed_id, guid);
+
+-- +goose Down
+ALTER TABLE posts
+DROP CONSTRAINT posts_feed_id_guid_key,
+ADD CONSTRAINT posts_url_key UNIQUE (url),
+DROP COLUMN guid;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE stars (
+    user_id UUID NOT NULL,
+    post_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    note VARCHAR NOT NULL DEFAULT '',
+    PRIMARY KEY (user_id, post_id),
+    CONSTRAINT fk_user_id
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    -- starred posts are kept for good: deleting one fails, so anything that
+    -- cleans up old posts has to skip them with
+    -- NOT EXISTS (SELECT 1 FROM stars WHERE stars.post_id = posts.id)
+    CONSTRAINT fk_post_id
+        FOREIGN KEY(post_id)
+        REFERENCES posts(id)
+        ON DELETE RESTRICT
+);
+
+-- +goose Down
+DROP TABLE stars;
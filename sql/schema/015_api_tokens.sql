@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE api_tokens (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    name VARCHAR NOT NULL,
+    token_hash VARCHAR UNIQUE NOT NULL,
+    -- What the token may be used for: the JSON API, or one of the client APIs
+    -- whose keys are derived from a password and must not open the JSON API.
+    scope VARCHAR NOT NULL DEFAULT 'api'
+        CHECK (scope IN ('api', 'fever', 'greader', 'feed')),
+    CONSTRAINT fk_user_id
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE api_tokens;
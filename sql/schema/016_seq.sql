@@ -0,0 +1,15 @@
+-- +goose Up
+-- Sequential numbers for feeds and posts, for client APIs (Fever, Google
+-- Reader) that can only identify things by integer.
+ALTER TABLE feeds
+ADD COLUMN seq BIGSERIAL UNIQUE;
+
+ALTER TABLE posts
+ADD COLUMN seq BIGSERIAL UNIQUE;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN seq;
+
+ALTER TABLE posts
+DROP COLUMN seq;